@@ -0,0 +1,137 @@
+/*
+*
+Package capture records the traffic of a display transport to a file and
+replays it back, to reproduce field problems without the device.
+
+The file format is JSON Lines, one Record per line:
+
+	{"time":"2021-09-10T15:01:52.104Z","dir":"tx","data":"FE 00"}
+	{"time":"2021-09-10T15:01:52.112Z","dir":"rx","data":"FC 00 00 02 01 00"}
+
+*
+*/
+package capture
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Direction of a chunk of bytes, seen from the host.
+type Direction string
+
+const (
+	// TX are the bytes written to the device.
+	TX Direction = "tx"
+	// RX are the bytes read from the device.
+	RX Direction = "rx"
+)
+
+// Record is a chunk of bytes of a single Write (TX) or Read (RX).
+type Record struct {
+	Time time.Time
+	Dir  Direction
+	Data []byte
+}
+
+type jsonRecord struct {
+	Time time.Time `json:"time"`
+	Dir  Direction `json:"dir"`
+	Data string    `json:"data"`
+}
+
+func (r Record) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonRecord{
+		Time: r.Time,
+		Dir:  r.Dir,
+		Data: fmt.Sprintf("% X", r.Data),
+	})
+}
+
+func (r *Record) UnmarshalJSON(b []byte) error {
+	var v jsonRecord
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.Dir != TX && v.Dir != RX {
+		return fmt.Errorf("unknown direction: %q", v.Dir)
+	}
+	data, err := hex.DecodeString(strings.Join(strings.Fields(v.Data), ""))
+	if err != nil {
+		return fmt.Errorf("bad data: %w", err)
+	}
+	r.Time = v.Time
+	r.Dir = v.Dir
+	r.Data = data
+	return nil
+}
+
+// Writer writes records to a capture file. It is safe for concurrent use.
+type Writer struct {
+	mux sync.Mutex
+	enc *json.Encoder
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{enc: json.NewEncoder(w)}
+}
+
+func (w *Writer) Write(rec Record) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return w.enc.Encode(rec)
+}
+
+// Reader reads records from a capture file.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &Reader{scanner: scanner}
+}
+
+// Read returns the next record, io.EOF at the end of the capture.
+func (r *Reader) Read() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := strings.TrimSpace(r.scanner.Text())
+		if len(line) <= 0 {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return Record{}, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		return rec, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+// ReadAll reads every record of a capture file.
+func ReadAll(r io.Reader) ([]Record, error) {
+	reader := NewReader(r)
+	records := make([]Record, 0)
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+}
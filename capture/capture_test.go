@@ -0,0 +1,135 @@
+package capture_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dumacp/matrixorbital/capture"
+	"github.com/dumacp/matrixorbital/gtt43a"
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
+)
+
+// readTimeout is the ReadTimeout of the emulator and replayer ports: their
+// reads return io.EOF when nothing arrives in time, like a serial port.
+const readTimeout = 100 * time.Millisecond
+
+func TestRecordAndReplay(t *testing.T) {
+	e := emulator.New(&emulator.Options{Version: []byte{0x02, 0x05}})
+	defer e.Close()
+
+	var file bytes.Buffer
+	rec := capture.NewRecorder(e.Port(), &file)
+	m := gtt43a.NewDisplayWithTransport(rec, &gtt43a.PortOptions{ReadTimeout: readTimeout})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	if _, err := m.Version(); err != nil {
+		t.Fatalf("version: %s", err)
+	}
+	if _, err := m.Echo([]byte("hi")); err != nil {
+		t.Fatalf("echo: %s", err)
+	}
+	m.Close()
+	if err := rec.Err(); err != nil {
+		t.Fatalf("recorder: %s", err)
+	}
+
+	records, err := capture.ReadAll(bytes.NewReader(file.Bytes()))
+	if err != nil {
+		t.Fatalf("read capture: %s", err)
+	}
+	want := []capture.Record{
+		{Dir: capture.TX, Data: []byte{0xFE, 0x00}},
+		{Dir: capture.RX, Data: []byte{0xFC, 0x00, 0x00, 0x02, 0x02, 0x05}},
+		{Dir: capture.TX, Data: []byte{0xFE, 0xFF, 'h', 'i'}},
+		{Dir: capture.RX, Data: []byte{0xFC, 0xFF, 0x00, 0x02, 'h', 'i'}},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("records: %v", records)
+	}
+	for i := range want {
+		if records[i].Dir != want[i].Dir || !bytes.Equal(records[i].Data, want[i].Data) || records[i].Time.IsZero() {
+			t.Errorf("record %d: %+v, want %+v", i, records[i], want[i])
+		}
+	}
+
+	rp, err := capture.NewReplayer(bytes.NewReader(file.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("replayer: %s", err)
+	}
+	m = gtt43a.NewDisplayWithTransport(rp, &gtt43a.PortOptions{ReadTimeout: readTimeout})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer m.Close()
+	version, err := m.Version()
+	if err != nil || !bytes.Equal(version, []byte{0x02, 0x05}) {
+		t.Errorf("replayed version: [% X], %v", version, err)
+	}
+	echo, err := m.Echo([]byte("hi"))
+	if err != nil || string(echo) != "hi" {
+		t.Errorf("replayed echo: %q, %v", echo, err)
+	}
+	select {
+	case <-rp.Done():
+	default:
+		t.Errorf("replay not done")
+	}
+}
+
+func TestReplaySplitEvent(t *testing.T) {
+	file := strings.Join([]string{
+		`{"time":"2021-09-10T15:01:52.100Z","dir":"rx","data":"FC EB 00 05 15 00"}`,
+		`{"time":"2021-09-10T15:01:52.110Z","dir":"rx","data":"00 05 01 FC 87"}`,
+		`{"time":"2021-09-10T15:01:52.120Z","dir":"rx","data":"00 02 01 07"}`,
+	}, "\n")
+	rp, err := capture.NewReplayer(strings.NewReader(file), &capture.ReplayOptions{Realtime: true})
+	if err != nil {
+		t.Fatalf("replayer: %s", err)
+	}
+
+	m := gtt43a.NewDisplayWithTransport(rp, &gtt43a.PortOptions{ReadTimeout: readTimeout})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer m.Close()
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	events, err := m.Events()
+	if err != nil {
+		t.Fatalf("events: %s", err)
+	}
+
+	want := []struct {
+		typ   gtt43a.EventType
+		objID uint16
+	}{
+		{gtt43a.ButtonClick, 5},
+		{gtt43a.RegionTouch, 7},
+	}
+	for _, w := range want {
+		select {
+		case evt := <-events:
+			if evt.Type != w.typ || evt.ObjId != w.objID {
+				t.Errorf("event: %+v, want %+v", evt, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting event %+v", w)
+		}
+	}
+}
+
+func TestReadBadCapture(t *testing.T) {
+	for _, file := range []string{
+		`{"time":"2021-09-10T15:01:52.100Z","dir":"xx","data":"FC"}`,
+		`{"time":"2021-09-10T15:01:52.100Z","dir":"rx","data":"FG"}`,
+		`not json`,
+	} {
+		if _, err := capture.ReadAll(strings.NewReader(file)); err == nil {
+			t.Errorf("expected error reading %q", file)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package capture
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Recorder is a transport that forwards to another transport and writes
+// every chunk written and read, with its timestamp, to a capture file.
+type Recorder struct {
+	rw  io.ReadWriteCloser
+	w   *Writer
+	mux sync.Mutex
+	err error
+}
+
+// NewRecorder wraps rw and records its traffic to w. Closing the Recorder
+// closes rw but not w.
+func NewRecorder(rw io.ReadWriteCloser, w io.Writer) *Recorder {
+	return &Recorder{rw: rw, w: NewWriter(w)}
+}
+
+func (r *Recorder) Read(p []byte) (int, error) {
+	n, err := r.rw.Read(p)
+	if n > 0 {
+		r.record(RX, p[:n])
+	}
+	return n, err
+}
+
+// Write records p before writing it, so the capture never shows a reply
+// before the request that caused it.
+func (r *Recorder) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		r.record(TX, p)
+	}
+	return r.rw.Write(p)
+}
+
+func (r *Recorder) Close() error {
+	return r.rw.Close()
+}
+
+// Err returns the first error writing the capture file. Capture errors never
+// interrupt the traffic of the transport.
+func (r *Recorder) Err() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.err
+}
+
+func (r *Recorder) record(dir Direction, data []byte) {
+	rec := Record{
+		Time: time.Now().UTC(),
+		Dir:  dir,
+		Data: append([]byte{}, data...),
+	}
+	if err := r.w.Write(rec); err != nil {
+		r.mux.Lock()
+		if r.err == nil {
+			r.err = err
+		}
+		r.mux.Unlock()
+	}
+}
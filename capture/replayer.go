@@ -0,0 +1,164 @@
+package capture
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReplayOptions of a Replayer
+type ReplayOptions struct {
+	// Realtime keeps the original delays between the RX chunks.
+	Realtime bool
+	// IgnoreTX releases every RX chunk at once, instead of waiting for the
+	// host to write as many chunks as were written before it in the capture.
+	IgnoreTX bool
+	// ReadTimeout is the time a Read waits for a chunk before returning
+	// 0, io.EOF, like a serial port opened with ReadTimeout. 0 means 100 ms.
+	// Set the same PortOptions.ReadTimeout on the display.
+	ReadTimeout time.Duration
+}
+
+type replayChunk struct {
+	data    []byte
+	afterTX int64
+	delay   time.Duration
+}
+
+// Replayer is a transport that feeds the RX chunks of a capture back to the
+// host, each in a single Read as originally read. Written bytes are
+// discarded, they only pace the replay.
+type Replayer struct {
+	written int64
+	options ReplayOptions
+	chunks  []replayChunk
+	wrote   chan struct{}
+	closed  chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	closing sync.Once
+
+	mux  sync.Mutex
+	pos  int
+	rest []byte
+}
+
+// NewReplayer reads a capture file from r. opt can be nil.
+func NewReplayer(r io.Reader, opt *ReplayOptions) (*Replayer, error) {
+	records, err := ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayerRecords(records, opt), nil
+}
+
+// NewReplayerRecords replays records. opt can be nil.
+func NewReplayerRecords(records []Record, opt *ReplayOptions) *Replayer {
+	rp := &Replayer{}
+	if opt != nil {
+		rp.options = *opt
+	}
+	if rp.options.ReadTimeout <= 0 {
+		rp.options.ReadTimeout = 100 * time.Millisecond
+	}
+	var tx int64
+	var last time.Time
+	for _, rec := range records {
+		if rec.Dir == TX {
+			tx++
+			continue
+		}
+		chunk := replayChunk{data: rec.Data, afterTX: tx}
+		if !last.IsZero() && rec.Time.After(last) {
+			chunk.delay = rec.Time.Sub(last)
+		}
+		last = rec.Time
+		rp.chunks = append(rp.chunks, chunk)
+	}
+	rp.wrote = make(chan struct{}, 1)
+	rp.closed = make(chan struct{})
+	rp.done = make(chan struct{})
+	if len(rp.chunks) <= 0 {
+		close(rp.done)
+	}
+	return rp
+}
+
+// Done is closed when every RX chunk has been read by the host.
+func (rp *Replayer) Done() <-chan struct{} {
+	return rp.done
+}
+
+func (rp *Replayer) Read(p []byte) (int, error) {
+	rp.mux.Lock()
+	defer rp.mux.Unlock()
+
+	select {
+	case <-rp.closed:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+
+	if len(rp.rest) <= 0 {
+		timer := time.NewTimer(rp.options.ReadTimeout)
+		defer timer.Stop()
+		if rp.pos >= len(rp.chunks) {
+			select {
+			case <-rp.closed:
+				return 0, io.ErrClosedPipe
+			case <-timer.C:
+				return 0, io.EOF
+			}
+		}
+		chunk := rp.chunks[rp.pos]
+		for !rp.options.IgnoreTX && atomic.LoadInt64(&rp.written) < chunk.afterTX {
+			select {
+			case <-rp.wrote:
+			case <-rp.closed:
+				return 0, io.ErrClosedPipe
+			case <-timer.C:
+				return 0, io.EOF
+			}
+		}
+		if rp.options.Realtime && chunk.delay > 0 {
+			select {
+			case <-time.After(chunk.delay):
+			case <-rp.closed:
+				return 0, io.ErrClosedPipe
+			}
+		}
+		rp.rest = chunk.data
+		rp.pos++
+	}
+
+	n := copy(p, rp.rest)
+	rp.rest = rp.rest[n:]
+	if len(rp.rest) <= 0 && rp.pos >= len(rp.chunks) {
+		rp.once.Do(func() {
+			close(rp.done)
+		})
+	}
+	return n, nil
+}
+
+func (rp *Replayer) Write(p []byte) (int, error) {
+	select {
+	case <-rp.closed:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	atomic.AddInt64(&rp.written, 1)
+	select {
+	case rp.wrote <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+func (rp *Replayer) Close() error {
+	rp.closing.Do(func() {
+		close(rp.closed)
+	})
+	return nil
+}
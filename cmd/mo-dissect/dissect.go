@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/dumacp/matrixorbital/gtt43a"
+)
+
+// Device selects the command names table.
+type Device string
+
+const (
+	GTT43A   Device = "gtt43a"
+	GLK19264 Device = "glk19264a"
+)
+
+var gttCommands = map[byte]string{
+	0x00: "Version",
+	0x01: "RunReset",
+	0x10: "CreateLabelLegacy",
+	0x11: "UpdateLabel",
+	0x19: "SetLabelBackgroundColour",
+	0x24: "PrintUnicode",
+	0x25: "PrintUTF8String",
+	0x2B: "TextWindow",
+	0x2E: "TextColour",
+	0x33: "FontSize",
+	0x58: "ClrScreen",
+	0x5D: "RunScript",
+	0x5F: "LoadBitmapLegacy",
+	0x61: "DisplayBitmapLegacy",
+	0x62: "SetBitmapTransparencyLegacy",
+	0x69: "UpdateBargraphValue",
+	0x75: "UpdateTraceValue",
+	0x79: "TextInsertPoint",
+	0x7A: "GetTextPoint",
+	0x87: "ChangeTouchReporting",
+	0x88: "GetTouchReporting",
+	0x99: "SetBacklight",
+	0xA7: "GetSliderValue",
+	0xAB: "GetToggleState",
+	0xBB: "BuzzerActive",
+	0xC2: "AnimationStartStop",
+	0xC3: "AnimationSetFrame",
+	0xC6: "AnimationStopAll",
+	0xCC: "WriteScratch",
+	0xCD: "ReadScratch",
+	0xD0: "ClearBitmapLegacy",
+	0xFA: "GTT25",
+	0xFF: "Echo",
+}
+
+var glkCommands = map[byte]string{
+	0x26: "PollKey",
+	0x2A: "SetTextWindow",
+	0x2B: "InitTextWindow",
+	0x2C: "ClrWindow",
+	0x31: "Font",
+	0x36: "ReadVersion",
+	0x41: "AutoTransmKeyOn",
+	0x42: "BackLigthON",
+	0x46: "BackLigthOff",
+	0x47: "ColRow",
+	0x4F: "AutoTransmKeyOff",
+	0x58: "ClrScreen",
+	0x5A: "Led",
+	0x5E: "BitmapUpload",
+	0x62: "BitmapDraw",
+	0x64: "BitmapDrawData",
+	0x72: "Rectangle",
+	0x9B: "KeyPadOff",
+	0x9C: "KeyPadON",
+	0xBB: "BuzzerActive",
+	0xCC: "WriteScratch",
+	0xCD: "ReadScratch",
+}
+
+// gttResponses names the device frames that are not replies to a command
+// of the same code.
+var gttResponses = map[byte]string{
+	0xFB: "ScriptProgress",
+}
+
+type method struct {
+	name string
+	// args formats the arguments after the sub command.
+	args func(args []byte) string
+	// value formats the value of a successful reply.
+	value func(res *gtt43a.Response) string
+}
+
+var gtt25Methods = map[[2]byte]method{
+	{0x01, 0x00}: {name: "CreateObject", args: createArgs},
+	{0x01, 0x01}: {name: "DestroyObject", args: idArgs},
+	{0x01, 0x04}: {name: "SetPropertyValueU8", args: propertyArgs(1)},
+	{0x01, 0x05}: {name: "GetPropertyValueU8", args: propertyArgs(0), value: func(res *gtt43a.Response) string {
+		v, err := res.Uint8()
+		return valueString(v, err)
+	}},
+	{0x01, 0x06}: {name: "SetPropertyValueU16", args: propertyArgs(2)},
+	{0x01, 0x07}: {name: "GetPropertyValueU16", args: propertyArgs(0), value: func(res *gtt43a.Response) string {
+		v, err := res.Uint16()
+		return valueString(v, err)
+	}},
+	{0x01, 0x08}: {name: "SetPropertyValueS16", args: propertyArgs(-2)},
+	{0x01, 0x09}: {name: "GetPropertyValueS16", args: propertyArgs(0), value: func(res *gtt43a.Response) string {
+		v, err := res.Int16()
+		return valueString(v, err)
+	}},
+	{0x01, 0x0A}: {name: "SetPropertyText", args: textArgs},
+	{0x01, 0x0B}: {name: "GetPropertyText", args: propertyArgs(0), value: func(res *gtt43a.Response) string {
+		v, err := res.Text()
+		if err != nil {
+			return err.Error()
+		}
+		return fmt.Sprintf("text=%q", v)
+	}},
+	{0x01, 0x0C}: {name: "SetPropertyValueS32", args: propertyArgs(-4)},
+	{0x01, 0x0D}: {name: "GetPropertyValueS32", args: propertyArgs(0), value: func(res *gtt43a.Response) string {
+		v, err := res.Int32()
+		return valueString(v, err)
+	}},
+	{0x01, 0x0E}: {name: "SetPropertyBool", args: propertyArgs(1)},
+	{0x01, 0x0F}: {name: "GetPropertyBool", args: propertyArgs(0), value: func(res *gtt43a.Response) string {
+		v, err := res.Bool()
+		return valueString(v, err)
+	}},
+	{0x02, 0x02}: {name: "SetFocus", args: idArgs},
+	{0x0D, 0x00}: {name: "BitmapLoad", args: idArgs},
+	{0x0D, 0x01}: {name: "BitmapCapture", args: idArgs},
+	{0x1A, 0x03}: {name: "ObjectListGet", args: idArgs},
+	{0x1F, 0x00}: {name: "BeginUpdate", args: idArgs},
+	{0x1F, 0x01}: {name: "EndUpdate", args: idArgs},
+}
+
+func propertyName(b []byte) string {
+	if info, ok := gtt43a.LookupProperty(b); ok {
+		return fmt.Sprintf("%s(%X)", info.Name, b)
+	}
+	return fmt.Sprintf("%X", b)
+}
+
+func objectTypeName(b []byte) string {
+	for _, v := range gtt43a.ObjectTypes() {
+		if string(v.Type) == string(b) {
+			return fmt.Sprintf("%s(%X)", v.Name, b)
+		}
+	}
+	return fmt.Sprintf("%X", b)
+}
+
+func valueString(v interface{}, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("value=%v", v)
+}
+
+func rest(b []byte) string {
+	if len(b) <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" data=[% X]", b)
+}
+
+func idArgs(args []byte) string {
+	if len(args) < 2 {
+		return rest(args)
+	}
+	return fmt.Sprintf("id=%d", binary.BigEndian.Uint16(args)) + rest(args[2:])
+}
+
+func createArgs(args []byte) string {
+	if len(args) < 4 {
+		return rest(args)
+	}
+	return fmt.Sprintf("id=%d type=%s", binary.BigEndian.Uint16(args[2:4]), objectTypeName(args[:2])) + rest(args[4:])
+}
+
+// propertyArgs formats id, property and a value of size bytes, negative for
+// signed values.
+func propertyArgs(size int) func(args []byte) string {
+	return func(args []byte) string {
+		if len(args) < 4 {
+			return rest(args)
+		}
+		s := fmt.Sprintf("id=%d prop=%s", binary.BigEndian.Uint16(args), propertyName(args[2:4]))
+		value := args[4:]
+		switch {
+		case size == 1 && len(value) >= 1:
+			s += fmt.Sprintf(" value=%d", value[0])
+			value = value[1:]
+		case size == 2 && len(value) >= 2:
+			s += fmt.Sprintf(" value=%d", binary.BigEndian.Uint16(value))
+			value = value[2:]
+		case size == -2 && len(value) >= 2:
+			s += fmt.Sprintf(" value=%d", int16(binary.BigEndian.Uint16(value)))
+			value = value[2:]
+		case size == -4 && len(value) >= 4:
+			s += fmt.Sprintf(" value=%d", int32(binary.BigEndian.Uint32(value)))
+			value = value[4:]
+		}
+		return s + rest(value)
+	}
+}
+
+func textArgs(args []byte) string {
+	if len(args) < 7 {
+		return propertyArgs(0)(args)
+	}
+	s := propertyArgs(0)(args[:4])
+	size := int(binary.BigEndian.Uint16(args[5:7]))
+	text := args[7:]
+	if size > len(text) {
+		return s + rest(args[4:])
+	}
+	return s + fmt.Sprintf(" text=%q", decodeUTF16(text[:size])) + rest(text[size:])
+}
+
+func decodeUTF16(b []byte) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, binary.LittleEndian.Uint16(b[i:]))
+	}
+	return string(utf16.Decode(u))
+}
+
+// Dissector pretty prints the commands written to and the frames read from a
+// display, one line per command or frame.
+type Dissector struct {
+	w        io.Writer
+	device   Device
+	commands map[byte]string
+	decoder  gtt43a.FrameDecoder
+}
+
+func NewDissector(w io.Writer, device Device) *Dissector {
+	d := &Dissector{w: w, device: device, commands: gttCommands}
+	if device == GLK19264 {
+		d.commands = glkCommands
+	}
+	return d
+}
+
+// TX prints a command written by the host. A single write is a single
+// command, as the host commands have no length field.
+func (d *Dissector) TX(data []byte) {
+	d.print("tx", d.describeCommand(data), data)
+}
+
+// RX feeds bytes read from the device and prints every frame completed.
+func (d *Dissector) RX(data []byte) {
+	if d.device == GLK19264 {
+		d.print("rx", "Data", data)
+		return
+	}
+	for _, frame := range d.decoder.Decode(data) {
+		d.print("rx", d.describeFrame(frame), frame.Bytes())
+	}
+}
+
+// Flush prints the bytes of an incomplete frame, if any.
+func (d *Dissector) Flush() {
+	if n := d.decoder.Buffered(); n > 0 {
+		fmt.Fprintf(d.w, "rx %d bytes of incomplete frame\n", n)
+		d.decoder.Reset()
+	}
+}
+
+func (d *Dissector) print(dir, desc string, data []byte) {
+	fmt.Fprintf(d.w, "%s %s [% X]\n", dir, desc, data)
+}
+
+func (d *Dissector) commandName(cmd byte) string {
+	if name, ok := d.commands[cmd]; ok {
+		return name
+	}
+	return fmt.Sprintf("Cmd(%02X)", cmd)
+}
+
+func (d *Dissector) describeCommand(data []byte) string {
+	if len(data) < 2 || data[0] != 0xFE {
+		return "Unknown"
+	}
+	cmd, args := data[1], data[2:]
+	if d.device != GLK19264 && cmd == 0xFA && len(args) >= 2 {
+		sub := [2]byte{args[0], args[1]}
+		if m, ok := gtt25Methods[sub]; ok {
+			return strings.TrimSpace(fmt.Sprintf("GTT25 %s %s", m.name, m.args(args[2:])))
+		}
+		return fmt.Sprintf("GTT25 Method(%X)", args[:2]) + rest(args[2:])
+	}
+	switch {
+	case cmd == 0x5D && len(args) > 0:
+		return fmt.Sprintf("%s file=%q", d.commandName(cmd), strings.TrimRight(string(args), "\x00"))
+	case cmd == 0x25 || cmd == 0xFF:
+		return fmt.Sprintf("%s text=%q", d.commandName(cmd), args)
+	}
+	return d.commandName(cmd) + rest(args)
+}
+
+func (d *Dissector) describeFrame(frame gtt43a.Frame) string {
+	if event, ok := gtt43a.EventFromFrame(frame); ok {
+		if touch, ok := event.Payload.(gtt43a.TouchEvent); ok && event.Type == gtt43a.CoordinateTouch {
+			return fmt.Sprintf("Event CoordinateTouch %s x=%d y=%d", touch.Kind, touch.X, touch.Y)
+		}
+		if unknown, ok := event.Payload.(gtt43a.UnknownEvent); ok {
+			return fmt.Sprintf("Event Unknown id=%04X obj=%d", unknown.ID, event.ObjId) + rest(event.Value)
+		}
+		return fmt.Sprintf("Event %s obj=%d", event.Type, event.ObjId) + rest(event.Value)
+	}
+	switch frame.Cmd {
+	case 0xEB, 0x86, 0x87:
+		return "Event Unknown" + rest(frame.Payload)
+	case 0xFA:
+		res, err := gtt43a.NewResponse(frame)
+		if err != nil {
+			return fmt.Sprintf("Response GTT25 %s", err)
+		}
+		name := fmt.Sprintf("Method(%X)", res.SubCmd)
+		var m method
+		if len(res.SubCmd) == 2 {
+			var ok bool
+			if m, ok = gtt25Methods[[2]byte{res.SubCmd[0], res.SubCmd[1]}]; ok {
+				name = m.name
+			}
+		}
+		s := fmt.Sprintf("Response GTT25 %s status=%s", name, res.Status)
+		if res.Err() == nil && m.value != nil {
+			return s + " " + m.value(res)
+		}
+		return s + rest(res.Payload)
+	}
+	if name, ok := gttResponses[frame.Cmd]; ok {
+		return name + rest(frame.Payload)
+	}
+	return "Response " + d.commandName(frame.Cmd) + rest(frame.Payload)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDissectHex(t *testing.T) {
+	input := `
+# comment
+FE 58
+tx FE FA 01 06 00 05 09 0A 00 10
+FC FA 00 05 01 07 FE 00 10 FC EB 00 05
+15 00 00 05 01
+FE FA 01 0A 00 06 09 06 00 00 04 68 00 69 00
+rx FC FA 00 03 01 00 F8
+FE 5D 73 63 72 69 70 74 00
+FC 87 00 02 01 07
+FC 86 00 05 02 01 2C 00 C8 FC 00
+`
+	want := []string{
+		"tx ClrScreen [FE 58]",
+		"tx GTT25 SetPropertyValueU16 id=5 prop=LabelFontSize(090A) value=16 [FE FA 01 06 00 05 09 0A 00 10]",
+		"rx Response GTT25 GetPropertyValueU16 status=success value=16 [FC FA 00 05 01 07 FE 00 10]",
+		"rx Event ButtonClick obj=5 data=[01] [FC EB 00 05 15 00 00 05 01]",
+		`tx GTT25 SetPropertyText id=6 prop=LabelText(0906) text="hi" [FE FA 01 0A 00 06 09 06 00 00 04 68 00 69 00]`,
+		"rx Response GTT25 CreateObject status=object ID in use [FC FA 00 03 01 00 F8]",
+		`tx RunScript file="script" [FE 5D 73 63 72 69 70 74 00]`,
+		"rx Event RegionTouch obj=7 data=[01] [FC 87 00 02 01 07]",
+		"rx Event CoordinateTouch drag x=300 y=200 [FC 86 00 05 02 01 2C 00 C8]",
+		"rx 2 bytes of incomplete frame",
+	}
+
+	var out bytes.Buffer
+	d := NewDissector(&out, GTT43A)
+	if err := dissectHex(d, strings.NewReader(input)); err != nil {
+		t.Fatalf("dissect: %s", err)
+	}
+	d.Flush()
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("got %d lines:\n%s", len(got), out.String())
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d:\n got: %s\nwant: %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestDissectCapture(t *testing.T) {
+	input := `{"time":"2021-09-10T15:01:52.100Z","dir":"tx","data":"FE 00"}
+{"time":"2021-09-10T15:01:52.110Z","dir":"rx","data":"FC 00 00 02"}
+{"time":"2021-09-10T15:01:52.120Z","dir":"rx","data":"02 05"}
+`
+	var out bytes.Buffer
+	d := NewDissector(&out, GTT43A)
+	if err := dissectCapture(d, strings.NewReader(input)); err != nil {
+		t.Fatalf("dissect: %s", err)
+	}
+	want := "tx Version [FE 00]\nrx Response Version data=[02 05] [FC 00 00 02 02 05]\n"
+	if out.String() != want {
+		t.Errorf("got:\n%swant:\n%s", out.String(), want)
+	}
+}
+
+func TestParseHexLine(t *testing.T) {
+	dir, data, err := parseHexLine("> [0xFE, 0x58]")
+	if err != nil || dir != "tx" || !bytes.Equal(data, []byte{0xFE, 0x58}) {
+		t.Errorf("got %q [% X] %v", dir, data, err)
+	}
+	if _, _, err := parseHexLine("FE 5G"); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,159 @@
+/*
+*
+mo-dissect pretty prints the traffic of a Matrix Orbital display.
+
+Usage:
+
+	mo-dissect [-device gtt43a|glk19264a] [-format auto|capture|hex] [file]
+
+The input (stdin without file) is a capture file written by
+capture.Recorder, or a hex dump with a chunk of bytes per line:
+
+	FE 58
+	tx FE FA 01 07 00 05 09 0A
+	rx FC FA 00 05 01 07 FE 00 10
+
+Lines without the "tx"/"rx" prefix are commands when they start with 0xFE,
+else bytes read from the device. Text after '#' is ignored.
+*
+*/
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/dumacp/matrixorbital/capture"
+)
+
+func main() {
+	device := flag.String("device", string(GTT43A), "display model: gtt43a or glk19264a")
+	format := flag.String("format", "auto", "input format: auto, capture or hex")
+	flag.Parse()
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log.Fatalln(err)
+		}
+		defer f.Close()
+		in = f
+	}
+	switch Device(*device) {
+	case GTT43A, GLK19264:
+	default:
+		log.Fatalf("unknown device: %q", *device)
+	}
+
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if *format == "auto" {
+		*format = "hex"
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			*format = "capture"
+		}
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	d := NewDissector(w, Device(*device))
+	switch *format {
+	case "capture":
+		err = dissectCapture(d, bytes.NewReader(data))
+	case "hex":
+		err = dissectHex(d, bytes.NewReader(data))
+	default:
+		err = fmt.Errorf("unknown format: %q", *format)
+	}
+	d.Flush()
+	if err != nil {
+		w.Flush()
+		log.Fatalln(err)
+	}
+}
+
+func dissectCapture(d *Dissector, r io.Reader) error {
+	records, err := capture.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		switch rec.Dir {
+		case capture.TX:
+			d.TX(rec.Data)
+		case capture.RX:
+			d.RX(rec.Data)
+		}
+	}
+	return nil
+}
+
+func dissectHex(d *Dissector, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		dir, data, err := parseHexLine(scanner.Text())
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(data) <= 0 {
+			continue
+		}
+		if dir == "" {
+			dir = capture.RX
+			if data[0] == 0xFE {
+				dir = capture.TX
+			}
+		}
+		if dir == capture.TX {
+			d.TX(data)
+		} else {
+			d.RX(data)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseHexLine returns the bytes of a hex dump line, and its direction if
+// the line has a "tx"/"rx" prefix.
+func parseHexLine(line string) (capture.Direction, []byte, error) {
+	if idx := strings.IndexByte(line, '#'); idx >= 0 {
+		line = line[:idx]
+	}
+	line = strings.NewReplacer("[", " ", "]", " ", ",", " ").Replace(line)
+	fields := strings.Fields(line)
+	var dir capture.Direction
+	if len(fields) > 0 {
+		switch strings.ToLower(fields[0]) {
+		case "tx", ">":
+			dir = capture.TX
+			fields = fields[1:]
+		case "rx", "<":
+			dir = capture.RX
+			fields = fields[1:]
+		}
+	}
+	data := make([]byte, 0)
+	for _, field := range fields {
+		field = strings.TrimPrefix(strings.TrimPrefix(field, "0x"), "0X")
+		b, err := hex.DecodeString(field)
+		if err != nil {
+			return "", nil, fmt.Errorf("bad hex %q", field)
+		}
+		data = append(data, b...)
+	}
+	return dir, data, nil
+}
@@ -1,8 +1,8 @@
 package gtt43a
 
 import (
+	"context"
 	"encoding/binary"
-	"fmt"
 	"unicode/utf16"
 )
 
@@ -50,6 +50,11 @@ func (m *display) SetBitmapTransparencyLegacy(id int, r, g, b int) error {
 
 //Load in display memory a bitmap object from filename. The filename path in a local in display device.
 func (m *display) BitmapLoad(id int, filename string) error {
+	return m.BitmapLoadContext(context.Background(), id, filename)
+}
+
+// BitmapLoadContext is BitmapLoad bounded by ctx.
+func (m *display) BitmapLoadContext(ctx context.Context, id int, filename string) error {
 	prefix := []byte{0xFE, 0xFA}
 	prefix = append(prefix, Bitmap_Load.Value()...)
 	idb := make([]byte, 2)
@@ -76,20 +81,18 @@ func (m *display) BitmapLoad(id int, filename string) error {
 	data = append(data, prefix...)
 	data = append(data, filepacket...)
 
-	res, err := m.SendRecv(data)
-	if err != nil {
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
 		return err
 	}
-	if len(res) < 3 {
-		return fmt.Errorf("error in response: [% X]", res)
-	}
-	if res[len(res)-1] != byte(0xFE) {
-		return fmt.Errorf("error in request U8, status code: [%X], [% X]", res[2], res)
-	}
 	return nil
 }
 
 func (m *display) BitmapCapture(id int, left, top, width, height int) error {
+	return m.BitmapCaptureContext(context.Background(), id, left, top, width, height)
+}
+
+// BitmapCaptureContext is BitmapCapture bounded by ctx.
+func (m *display) BitmapCaptureContext(ctx context.Context, id int, left, top, width, height int) error {
 	prefix := []byte{0xFE, 0xFA}
 	prefix = append(prefix, Bitmap_Capture.Value()...)
 	idb := make([]byte, 2)
@@ -112,15 +115,8 @@ func (m *display) BitmapCapture(id int, left, top, width, height int) error {
 	data = append(data, widthb...)
 	data = append(data, heightb...)
 
-	res, err := m.SendRecv(data)
-	if err != nil {
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
 		return err
 	}
-	if len(res) < 3 {
-		return fmt.Errorf("error in response: [% X]", res)
-	}
-	if res[len(res)-1] != byte(0xFE) {
-		return fmt.Errorf("error in request U8, status code: [%X], [% X]", res[2], res)
-	}
 	return nil
 }
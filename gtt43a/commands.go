@@ -1,8 +1,8 @@
 package gtt43a
 
 import (
+	"context"
 	"encoding/binary"
-	"fmt"
 )
 
 type GTT25CommandType []byte
@@ -22,6 +22,11 @@ func (typeP GTT25CommandType) Value() []byte {
 }
 
 func (m *display) BaseObjectBeginUpdate(id int) error {
+	return m.BaseObjectBeginUpdateContext(context.Background(), id)
+}
+
+// BaseObjectBeginUpdateContext is BaseObjectBeginUpdate bounded by ctx.
+func (m *display) BaseObjectBeginUpdateContext(ctx context.Context, id int) error {
 
 	data := []byte{0xFE, 0xFA}
 	data = append(data, Begin_Update.Value()...)
@@ -30,21 +35,19 @@ func (m *display) BaseObjectBeginUpdate(id int) error {
 	data = append(data, idb...)
 
 	/**/
-	res, err := m.SendRecv(data)
-	if err != nil {
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
 		return err
 	}
-	if len(res) < 3 {
-		return fmt.Errorf("error in response: [% X]", res)
-	}
-	if res[len(res)-1] != byte(0xFE) {
-		return fmt.Errorf("error in request U16, status code: [%X]", res[2])
-	}
 	/**/
 	return nil
 }
 
 func (m *display) BaseObjectEndUpdate(id int) error {
+	return m.BaseObjectEndUpdateContext(context.Background(), id)
+}
+
+// BaseObjectEndUpdateContext is BaseObjectEndUpdate bounded by ctx.
+func (m *display) BaseObjectEndUpdateContext(ctx context.Context, id int) error {
 
 	data := []byte{0xFE, 0xFA}
 	data = append(data, End_Update.Value()...)
@@ -53,21 +56,19 @@ func (m *display) BaseObjectEndUpdate(id int) error {
 	data = append(data, idb...)
 
 	/**/
-	res, err := m.SendRecv(data)
-	if err != nil {
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
 		return err
 	}
-	if len(res) < 3 {
-		return fmt.Errorf("error in response: [% X]", res)
-	}
-	if res[len(res)-1] != byte(0xFE) {
-		return fmt.Errorf("error in request U16, status code: [%X]", res[2])
-	}
 	/**/
 	return nil
 }
 
 func (m *display) CreateObject(id int, objectType GTT25ObjectType) error {
+	return m.CreateObjectContext(context.Background(), id, objectType)
+}
+
+// CreateObjectContext is CreateObject bounded by ctx.
+func (m *display) CreateObjectContext(ctx context.Context, id int, objectType GTT25ObjectType) error {
 
 	data := []byte{0xFE, 0xFA}
 	data = append(data, Create_Object.Value()...)
@@ -76,20 +77,18 @@ func (m *display) CreateObject(id int, objectType GTT25ObjectType) error {
 	binary.BigEndian.PutUint16(idb, uint16(id))
 	data = append(data, idb...)
 
-	res, err := m.SendRecv(data)
-	if err != nil {
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
 		return err
 	}
-	if len(res) < 3 {
-		return fmt.Errorf("error in response: [% X]", res)
-	}
-	if res[len(res)-1] != byte(0xFE) {
-		return fmt.Errorf("error in request U16, status code: [%X]", res[2])
-	}
 	return nil
 }
 
 func (m *display) DestroyObject(id int) error {
+	return m.DestroyObjectContext(context.Background(), id)
+}
+
+// DestroyObjectContext is DestroyObject bounded by ctx.
+func (m *display) DestroyObjectContext(ctx context.Context, id int) error {
 
 	data := []byte{0xFE, 0xFA}
 	data = append(data, Destroy_Object.Value()...)
@@ -98,21 +97,19 @@ func (m *display) DestroyObject(id int) error {
 	data = append(data, idb...)
 
 	/**/
-	res, err := m.SendRecv(data)
-	if err != nil {
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
 		return err
 	}
-	if len(res) < 3 {
-		return fmt.Errorf("error in response: [% X]", res)
-	}
-	if res[len(res)-1] != byte(0xFE) {
-		return fmt.Errorf("error in request U16, status code: [%X]", res[2])
-	}
 	/**/
 	return nil
 }
 
 func (m *display) SetFocus(id int) error {
+	return m.SetFocusContext(context.Background(), id)
+}
+
+// SetFocusContext is SetFocus bounded by ctx.
+func (m *display) SetFocusContext(ctx context.Context, id int) error {
 
 	data := []byte{0xFE, 0xFA}
 	data = append(data, Set_Focus.Value()...)
@@ -121,21 +118,19 @@ func (m *display) SetFocus(id int) error {
 	data = append(data, idb...)
 
 	/**/
-	res, err := m.SendRecv(data)
-	if err != nil {
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
 		return err
 	}
-	if len(res) < 3 {
-		return fmt.Errorf("error in response: [% X]", res)
-	}
-	if res[len(res)-1] != byte(0xFE) {
-		return fmt.Errorf("error in request U16, status code: [%X]", res[2])
-	}
 	/**/
 	return nil
 }
 
 func (m *display) ObjectListGet(id, itemIndex int) error {
+	return m.ObjectListGetContext(context.Background(), id, itemIndex)
+}
+
+// ObjectListGetContext is ObjectListGet bounded by ctx.
+func (m *display) ObjectListGetContext(ctx context.Context, id, itemIndex int) error {
 
 	data := []byte{0xFE, 0xFA}
 	data = append(data, ObjectList_Get.Value()...)
@@ -147,16 +142,9 @@ func (m *display) ObjectListGet(id, itemIndex int) error {
 	data = append(data, indexb...)
 
 	/**/
-	res, err := m.SendRecv(data)
-	if err != nil {
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
 		return err
 	}
-	if len(res) < 3 {
-		return fmt.Errorf("error in response: [% X]", res)
-	}
-	if res[len(res)-1] != byte(0xFE) {
-		return fmt.Errorf("error in request SetFocus, status code: [%X]", res[2])
-	}
 	/**/
 	return nil
 }
@@ -0,0 +1,138 @@
+package gtt43a
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSendRecvCmdContext(t *testing.T) {
+	for _, listen := range []bool{false, true} {
+		m, _ := newEmulatedDisplay(t, nil)
+		if listen {
+			if err := m.Listen(); err != nil {
+				t.Fatalf("listen: %s", err)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		res, err := m.EchoContext(ctx, []byte{0x55})
+		cancel()
+		if err != nil || len(res) != 1 || res[0] != 0x55 {
+			t.Errorf("listen %v, echo: [% X], %v", listen, res, err)
+		}
+
+		// The emulator never answers command 0x42.
+		ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		_, err = m.SendRecvCmdContext(ctx, 0x42, nil)
+		cancel()
+		if !errors.Is(err, ErrorDevTimeout) {
+			t.Errorf("listen %v, expected ErrorDevTimeout, got %v", listen, err)
+		}
+		if elapsed := time.Since(start); elapsed >= timeoutRead {
+			t.Errorf("listen %v, deadline not applied: %s", listen, elapsed)
+		}
+
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		if _, err := m.GetPropertyValueU16Context(ctx, 1, Width)(); !errors.Is(err, context.Canceled) {
+			t.Errorf("listen %v, expected context.Canceled, got %v", listen, err)
+		}
+	}
+}
+
+// A transport whose Read blocks until data arrives, unlike the emulator port
+// that times out: the deadline of ctx must still apply.
+func TestContextBlockingRead(t *testing.T) {
+	for _, listen := range []bool{false, true} {
+		host, dev := net.Pipe()
+		// The peer never answers Version, and echoes the Echo requests.
+		go func() {
+			buf := make([]byte, 64)
+			for {
+				n, err := dev.Read(buf)
+				if err != nil {
+					return
+				}
+				if n < 2 || buf[0] != 0xFE || buf[1] != 0xFF {
+					continue
+				}
+				res := []byte{0xFC, 0xFF, 0x00, byte(n - 2)}
+				if _, err := dev.Write(append(res, buf[2:n]...)); err != nil {
+					return
+				}
+			}
+		}()
+		m := NewDisplayWithTransport(host, nil)
+		if err := m.Open(); err != nil {
+			t.Fatalf("open: %s", err)
+		}
+		if listen {
+			if err := m.Listen(); err != nil {
+				t.Fatalf("listen: %s", err)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		start := time.Now()
+		_, err := m.VersionContext(ctx)
+		cancel()
+		if !errors.Is(err, ErrorDevTimeout) {
+			t.Errorf("listen %v, expected ErrorDevTimeout, got %v", listen, err)
+		}
+		if elapsed := time.Since(start); elapsed >= time.Second {
+			t.Errorf("listen %v, deadline not applied: %s", listen, elapsed)
+		}
+
+		// The read left running gets the next reply.
+		res, err := m.Echo([]byte{0x55})
+		if err != nil || len(res) != 1 || res[0] != 0x55 {
+			t.Errorf("listen %v, echo: [% X], %v", listen, res, err)
+		}
+		m.Close()
+		dev.Close()
+	}
+}
+
+func TestContextVariantsCanceled(t *testing.T) {
+	m, _ := newEmulatedDisplay(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, request := range map[string]func() error{
+		"CreateObject":  func() error { return m.CreateObjectContext(ctx, 1, ObjectType_Label) },
+		"DestroyObject": func() error { return m.DestroyObjectContext(ctx, 1) },
+		"BeginUpdate":   func() error { return m.BaseObjectBeginUpdateContext(ctx, 1) },
+		"EndUpdate":     func() error { return m.BaseObjectEndUpdateContext(ctx, 1) },
+		"SetFocus":      func() error { return m.SetFocusContext(ctx, 1) },
+		"ObjectListGet": func() error { return m.ObjectListGetContext(ctx, 1, 0) },
+		"BitmapLoad":    func() error { return m.BitmapLoadContext(ctx, 1, "a.bmp") },
+		"BitmapCapture": func() error { return m.BitmapCaptureContext(ctx, 1, 0, 0, 10, 10) },
+		"Update": func() error {
+			return m.UpdateContext(ctx, 1, func(tx *Tx) error { return nil })
+		},
+		"GetToggleState": func() error {
+			_, err := m.GetToggleStateContext(ctx, 1)
+			return err
+		},
+		"GetSliderValue": func() error {
+			_, err := m.GetSliderValueContext(ctx, 1)
+			return err
+		},
+		"GetTouchReporting": func() error {
+			_, err := m.GetTouchReportingContext(ctx)
+			return err
+		},
+		"GetTextPoint": func() error {
+			_, _, err := m.GetTextPointContext(ctx)
+			return err
+		},
+	} {
+		if err := request(); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package gtt43a
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+// requestKey returns the key of the reply expected for a command: the
+// command code, plus the sub command for the GTT2.5 object commands (0xFA).
+// GTT2.5 replies don't carry the object ID, so requests to different objects
+// with the same sub command share a key and are answered in order.
+func requestKey(data []byte) (string, bool) {
+	if len(data) < 2 || data[0] != 0xFE {
+		return "", false
+	}
+	if data[1] == 0xFA {
+		if len(data) < 4 {
+			return "", false
+		}
+		return string(data[1:4]), true
+	}
+	return string(data[1:2]), true
+}
+
+// responseKey is the requestKey of the command answered by res.
+func responseKey(res *Response) string {
+	if res.Cmd == 0xFA {
+		return string(append([]byte{res.Cmd}, res.SubCmd...))
+	}
+	return string([]byte{res.Cmd})
+}
+
+// pendingRequest is a request waiting for its reply in listen mode.
+type pendingRequest struct {
+	key  string
+	data []byte
+	ch   chan *Response
+	// expire is set when the caller stops waiting: the entry stays in the
+	// queue until then, to absorb a late reply.
+	expire time.Time
+}
+
+// pendingTable matches the responses read by the listener with the
+// outstanding requests. The device answers in order, so the requests with the
+// same key are queued first in, first out.
+type pendingTable struct {
+	mux    sync.Mutex
+	queues map[string][]*pendingRequest
+}
+
+func newPendingTable() *pendingTable {
+	return &pendingTable{queues: make(map[string][]*pendingRequest)}
+}
+
+func (p *pendingRequest) abandoned() bool {
+	return !p.expire.IsZero()
+}
+
+// add queues a request. It must be called before the request is sent.
+//
+// A request identical to an abandoned one (a retry) takes its place, to get
+// whichever of the two replies comes first, and an abandoned entry valid for
+// ttl is queued for the other one. When only one reply comes, that entry
+// absorbs the reply of the next request with the same key: a timeout is
+// preferred to delivering the reply of another object.
+func (t *pendingTable) add(key string, data []byte, ttl time.Duration) *pendingRequest {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	now := time.Now()
+	queue := make([]*pendingRequest, 0, len(t.queues[key])+1)
+	var retried *pendingRequest
+	for _, v := range t.queues[key] {
+		if v.abandoned() && now.After(v.expire) {
+			continue
+		}
+		if retried == nil && v.abandoned() && bytes.Equal(v.data, data) {
+			retried = v
+		}
+		queue = append(queue, v)
+	}
+	p := &pendingRequest{key: key, data: data, ch: make(chan *Response, 1)}
+	if retried != nil {
+		retried.expire = time.Time{}
+		retried.ch = p.ch
+		p.ch = nil
+		p.expire = now.Add(ttl)
+		t.queues[key] = append(queue, p)
+		return retried
+	}
+	t.queues[key] = append(queue, p)
+	return p
+}
+
+// remove drops a request that was never sent.
+func (t *pendingTable) remove(p *pendingRequest) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	queue := t.queues[p.key]
+	for i, v := range queue {
+		if v == p {
+			t.queues[p.key] = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(t.queues[p.key]) <= 0 {
+		delete(t.queues, p.key)
+	}
+}
+
+// abandon marks a request whose caller stopped waiting. Its reply, if it
+// arrives within ttl, is discarded instead of going to the next request.
+func (t *pendingTable) abandon(p *pendingRequest, ttl time.Duration) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	p.expire = time.Now().Add(ttl)
+}
+
+// dispatch delivers res to the oldest request waiting for it. It returns
+// false if no request was waiting.
+func (t *pendingTable) dispatch(res *Response) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	key := responseKey(res)
+	queue := t.queues[key]
+	now := time.Now()
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if p.abandoned() && now.After(p.expire) {
+			// the reply of an abandoned request never arrived
+			continue
+		}
+		if len(queue) > 0 {
+			t.queues[key] = queue
+		} else {
+			delete(t.queues, key)
+		}
+		if !p.abandoned() {
+			p.ch <- res
+		}
+		return true
+	}
+	delete(t.queues, key)
+	return false
+}
+
+// sendRecvKey sends a command and waits for the reply with its requestKey.
+// When listening, concurrent callers only serialise the write: the reply is
+// routed by the pending table.
+func (m *display) sendRecvKey(ctx context.Context, data []byte) (*Response, error) {
+	ctx, cancel := m.requestContext(ctx)
+	defer cancel()
+	key, ok := requestKey(data)
+	var match func(*Response) bool
+	if ok {
+		match = func(res *Response) bool {
+			return responseKey(res) == key
+		}
+	}
+
+	m.wmux.Lock()
+	if ctx.Err() != nil {
+		m.wmux.Unlock()
+		return nil, contextError(ctx)
+	}
+	l := m.currentListener()
+	if !ok || l == nil {
+		defer m.wmux.Unlock()
+		if err := m.send(data); err != nil {
+			return nil, err
+		}
+		return m.recvContext(ctx, match)
+	}
+	p := l.pending.add(key, data, m.responseTimeout())
+	err := m.send(data)
+	m.wmux.Unlock()
+	if err != nil {
+		l.pending.remove(p)
+		return nil, err
+	}
+
+	return m.waitPending(ctx, l, p)
+}
+
+// waitPending waits the reply of a request sent in listen mode, until ctx is
+// done or the listener stops.
+func (m *display) waitPending(ctx context.Context, l *listener, p *pendingRequest) (*Response, error) {
+	select {
+	case res := <-p.ch:
+		return res, nil
+	case <-l.done:
+		select {
+		case res := <-p.ch:
+			return res, nil
+		default:
+		}
+		return nil, ErrorDevClosed
+	case <-ctx.Done():
+		l.pending.abandon(p, m.responseTimeout())
+		m.logger().Warn("response timeout", "err", ctx.Err())
+		return nil, contextError(ctx)
+	}
+}
@@ -0,0 +1,116 @@
+package gtt43a
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPendingTableLateReply(t *testing.T) {
+	getA := []byte{0xFE, 0xFA, 0x01, 0x07, 0x00, 0x01, 0x02, 0x03}
+	getB := []byte{0xFE, 0xFA, 0x01, 0x07, 0x00, 0x02, 0x02, 0x03}
+	replyA := &Response{Cmd: 0xFA, SubCmd: []byte{0x01, 0x07}, Status: StatusSuccess, Payload: []byte{0x00, 0x0A}}
+	replyB := &Response{Cmd: 0xFA, SubCmd: []byte{0x01, 0x07}, Status: StatusSuccess, Payload: []byte{0x00, 0x0B}}
+
+	table := newPendingTable()
+	key, _ := requestKey(getA)
+	a := table.add(key, getA, time.Second)
+	table.abandon(a, time.Second)
+	b := table.add(key, getB, time.Second)
+
+	// the late reply of A is absorbed, B gets its own reply
+	if !table.dispatch(replyA) {
+		t.Fatalf("late reply not absorbed")
+	}
+	select {
+	case res := <-b.ch:
+		t.Fatalf("B got the reply of A: % X", res.Payload)
+	default:
+	}
+	if !table.dispatch(replyB) {
+		t.Fatalf("reply of B not dispatched")
+	}
+	if res := <-b.ch; res != replyB {
+		t.Errorf("B got % X", res.Payload)
+	}
+	if table.dispatch(replyB) {
+		t.Errorf("unexpected reply dispatched")
+	}
+}
+
+func TestPendingTableRetry(t *testing.T) {
+	get := []byte{0xFE, 0xFA, 0x01, 0x07, 0x00, 0x01, 0x02, 0x03}
+	reply := &Response{Cmd: 0xFA, SubCmd: []byte{0x01, 0x07}, Status: StatusSuccess, Payload: []byte{0x00, 0x0A}}
+
+	table := newPendingTable()
+	key, _ := requestKey(get)
+	p := table.add(key, get, time.Second)
+	table.abandon(p, time.Second)
+
+	// the retry gets the first reply, late or not, the second is absorbed
+	p = table.add(key, get, time.Second)
+	if !table.dispatch(reply) {
+		t.Fatalf("reply not dispatched")
+	}
+	if res := <-p.ch; res != reply {
+		t.Errorf("retry got % X", res.Payload)
+	}
+	if !table.dispatch(reply) {
+		t.Errorf("second reply not absorbed")
+	}
+
+	// expired entries don't absorb replies
+	p = table.add(key, get, time.Second)
+	table.abandon(p, 0)
+	time.Sleep(time.Millisecond)
+	if table.dispatch(reply) {
+		t.Errorf("reply absorbed by an expired entry")
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+	for id := 1; id <= 8; id++ {
+		e.AddObject(id, ObjectType_VisualBitmap)
+		e.SetProperty(id, Width, []byte{0x00, byte(id * 10)})
+	}
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for id := 1; id <= 8; id++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				value, err := m.GetPropertyValueU16(id, Width)()
+				if err != nil {
+					errs <- err
+					return
+				}
+				if int(value) != id*10 {
+					errs <- fmt.Errorf("object %d: value %d", id, value)
+					return
+				}
+				echo := fmt.Sprintf("%d-%d", id, i)
+				res, err := m.Echo([]byte(echo))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if string(res) != echo {
+					errs <- fmt.Errorf("echo %q: %q", echo, res)
+					return
+				}
+			}
+		}(id)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
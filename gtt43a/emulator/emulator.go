@@ -0,0 +1,459 @@
+/*
+*
+Package emulator implements the device side of the gtt43a protocol, so the
+gtt43a package can be exercised without a panel attached.
+
+The emulator answers Version (0xFE 0x00), Echo (0xFE 0xFF), Reset (0xFE 0x01),
+RunScript (0xFE 0x5D), Write/ReadScratch (0xFE 0xCC, 0xFE 0xCD), touch
+reporting (0xFE 0x87, 0xFE 0x88), GetToggleState (0xFE 0xAB) and the GTT2.5
+object commands (0xFE 0xFA), and can inject event frames (0xFC 0xEB,
+0xFC 0x86, 0xFC 0x87). Every Write on the host port is handled as exactly one
+command.
+*
+*/
+package emulator
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+)
+
+// GTT2.5 status codes sent back in 0xFC 0xFA replies.
+const (
+	StatusSuccess         byte = 0xFE
+	StatusFailed          byte = 0xFD
+	StatusInvalidObjectID byte = 0xFC
+	StatusInvalidProperty byte = 0xFB
+	StatusInvalidType     byte = 0xFA
+	StatusOutOfRange      byte = 0xF9
+	StatusObjectIDInUse   byte = 0xF8
+	StatusOutOfMemory     byte = 0xF7
+	StatusInvalidMethod   byte = 0xF6
+)
+
+// Options of the emulated device
+type Options struct {
+	// Version is the payload of the Version reply.
+	Version []byte
+	// ReadTimeout of the host port, 0 means 100 ms. Its reads return
+	// 0, io.EOF on timeout: set the same PortOptions.ReadTimeout.
+	ReadTimeout time.Duration
+	// ScratchSize is the size of the scratch memory, 0 means 4096 bytes.
+	ScratchSize int
+	// Latency delays the reply of every command, to emulate slow lines.
+	Latency time.Duration
+	// Strict makes the GTT2.5 commands fail with StatusInvalidObjectID for
+	// objects that were not created with CreateObject or AddObject.
+	Strict bool
+}
+
+type propKey struct {
+	id   uint16
+	prop [2]byte
+}
+
+// Emulator is an in-process GTT43A device.
+type Emulator struct {
+	options Options
+	host    *port
+	dev     *port
+	done    chan struct{}
+
+	mux      sync.Mutex
+	objects  map[uint16][2]byte
+	props    map[propKey][]byte
+	scratch  []byte
+	script   string
+	touch    byte
+	toggles  map[byte]byte
+	failNext []byte
+	drop     int
+	requests [][]byte
+}
+
+const (
+	defaultReadTimeout = 100 * time.Millisecond
+	defaultScratchSize = 4096
+)
+
+// New creates and starts an emulated device. opt can be nil.
+func New(opt *Options) *Emulator {
+	e := &Emulator{}
+	if opt != nil {
+		e.options = *opt
+	}
+	if e.options.ReadTimeout <= 0 {
+		e.options.ReadTimeout = defaultReadTimeout
+	}
+	if e.options.ScratchSize <= 0 {
+		e.options.ScratchSize = defaultScratchSize
+	}
+	if e.options.Version == nil {
+		e.options.Version = []byte{0x01, 0x00}
+	}
+	e.objects = make(map[uint16][2]byte)
+	e.props = make(map[propKey][]byte)
+	e.toggles = make(map[byte]byte)
+	e.scratch = make([]byte, e.options.ScratchSize)
+	e.host, e.dev = newPorts(e.options.ReadTimeout)
+	e.done = make(chan struct{})
+	go e.serve()
+	return e
+}
+
+// Port returns the host end of the line, to be used as gtt43a transport.
+func (e *Emulator) Port() io.ReadWriteCloser {
+	return e.host
+}
+
+// Close disconnects the line and stops the device.
+func (e *Emulator) Close() error {
+	e.dev.Close()
+	<-e.done
+	return nil
+}
+
+// Requests returns a copy of every command received, in order.
+func (e *Emulator) Requests() [][]byte {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	reqs := make([][]byte, 0, len(e.requests))
+	for _, v := range e.requests {
+		reqs = append(reqs, append([]byte{}, v...))
+	}
+	return reqs
+}
+
+// Script returns the filename of the last RunScript command.
+func (e *Emulator) Script() string {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return e.script
+}
+
+// TouchReporting returns the last touch reporting style set by the host.
+func (e *Emulator) TouchReporting() byte {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	return e.touch
+}
+
+// AddObject declares an object as if it had been loaded by a script.
+func (e *Emulator) AddObject(id int, objectType []byte) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	var typ [2]byte
+	copy(typ[:], objectType)
+	e.objects[uint16(id)] = typ
+}
+
+// Property returns the raw value stored for a property, nil if never set.
+func (e *Emulator) Property(id int, prop []byte) []byte {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	v, ok := e.props[newPropKey(uint16(id), prop)]
+	if !ok {
+		return nil
+	}
+	return append([]byte{}, v...)
+}
+
+// SetProperty stores the raw value returned by the property getters.
+func (e *Emulator) SetProperty(id int, prop []byte, value []byte) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.props[newPropKey(uint16(id), prop)] = append([]byte{}, value...)
+}
+
+// SetToggleState sets the state returned by GetToggleState (0xFE 0xAB).
+func (e *Emulator) SetToggleState(id int, state byte) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.toggles[byte(id)] = state
+}
+
+// FailNext makes the next GTT2.5 command reply with status instead of
+// being executed.
+func (e *Emulator) FailNext(status byte) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.failNext = append(e.failNext, status)
+}
+
+// Drop makes the device ignore the next n commands, as if they were lost on
+// the line.
+func (e *Emulator) Drop(n int) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+	e.drop += n
+}
+
+// Inject writes a raw frame to the host.
+func (e *Emulator) Inject(frame []byte) error {
+	_, err := e.dev.Write(frame)
+	return err
+}
+
+// InjectEvent sends a GTT2.5 event frame (0xFC 0xEB).
+func (e *Emulator) InjectEvent(eventID, objID int, payload []byte) error {
+	data := make([]byte, 4)
+	binary.LittleEndian.PutUint16(data[0:2], uint16(eventID))
+	binary.BigEndian.PutUint16(data[2:4], uint16(objID))
+	data = append(data, payload...)
+	return e.Inject(newFrame(0xEB, data))
+}
+
+// InjectRegionTouch sends a touch region report (0xFC 0x87).
+func (e *Emulator) InjectRegionTouch(state, region int) error {
+	return e.Inject(newFrame(0x87, []byte{byte(state), byte(region)}))
+}
+
+// InjectTouch sends a touch coordinate report (0xFC 0x86).
+func (e *Emulator) InjectTouch(kind, x, y int) error {
+	data := []byte{byte(kind), 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(data[1:3], uint16(x))
+	binary.BigEndian.PutUint16(data[3:5], uint16(y))
+	return e.Inject(newFrame(0x86, data))
+}
+
+func newPropKey(id uint16, prop []byte) propKey {
+	key := propKey{id: id}
+	copy(key.prop[:], prop)
+	return key
+}
+
+func newFrame(cmd byte, payload []byte) []byte {
+	frame := []byte{0xFC, cmd, 0x00, 0x00}
+	binary.BigEndian.PutUint16(frame[2:4], uint16(len(payload)))
+	return append(frame, payload...)
+}
+
+func (e *Emulator) reply(cmd byte, payload []byte) {
+	e.dev.Write(newFrame(cmd, payload))
+}
+
+func (e *Emulator) serve() {
+	defer close(e.done)
+	// Room for a WriteScratch of the whole scratch memory.
+	buf := make([]byte, 8192)
+	for {
+		n, err := e.dev.Read(buf)
+		if err != nil {
+			return
+		}
+		if n < 2 || buf[0] != 0xFE {
+			continue
+		}
+		req := append([]byte{}, buf[:n]...)
+		e.mux.Lock()
+		e.requests = append(e.requests, req)
+		drop := e.drop > 0
+		if drop {
+			e.drop--
+		}
+		e.mux.Unlock()
+		if drop {
+			continue
+		}
+		if e.options.Latency > 0 {
+			time.Sleep(e.options.Latency)
+		}
+		e.handle(req[1], req[2:])
+	}
+}
+
+func (e *Emulator) handle(cmd byte, data []byte) {
+	switch cmd {
+	case 0x00:
+		e.reply(0x00, e.options.Version)
+	case 0xFF:
+		e.reply(0xFF, data)
+	case 0x01:
+		e.mux.Lock()
+		e.objects = make(map[uint16][2]byte)
+		e.props = make(map[propKey][]byte)
+		e.toggles = make(map[byte]byte)
+		e.mux.Unlock()
+		for range make([]int, 4) {
+			e.reply(0xFB, nil)
+		}
+	case 0x5D:
+		name := data
+		for i, v := range data {
+			if v == 0x00 {
+				name = data[:i]
+				break
+			}
+		}
+		e.mux.Lock()
+		e.script = string(name)
+		e.mux.Unlock()
+		for range make([]int, 2) {
+			e.reply(0xFB, nil)
+		}
+	case 0x87:
+		if len(data) > 0 {
+			e.mux.Lock()
+			e.touch = data[0]
+			e.mux.Unlock()
+		}
+	case 0x88:
+		e.mux.Lock()
+		style := e.touch
+		e.mux.Unlock()
+		e.reply(0x88, []byte{style})
+	case 0xAB:
+		if len(data) < 1 {
+			return
+		}
+		e.mux.Lock()
+		state := e.toggles[data[0]]
+		e.mux.Unlock()
+		e.reply(0xAB, []byte{state})
+	case 0xCC:
+		if len(data) < 4 {
+			return
+		}
+		addr := int(binary.BigEndian.Uint16(data[0:2]))
+		size := int(binary.BigEndian.Uint16(data[2:4]))
+		value := data[4:]
+		if size < len(value) {
+			value = value[:size]
+		}
+		e.mux.Lock()
+		if addr < len(e.scratch) {
+			copy(e.scratch[addr:], value)
+		}
+		e.mux.Unlock()
+	case 0xCD:
+		if len(data) < 4 {
+			return
+		}
+		addr := int(binary.BigEndian.Uint16(data[0:2]))
+		size := int(binary.BigEndian.Uint16(data[2:4]))
+		e.mux.Lock()
+		if addr > len(e.scratch) {
+			addr = len(e.scratch)
+		}
+		if addr+size > len(e.scratch) {
+			size = len(e.scratch) - addr
+		}
+		res := make([]byte, 2, size+2)
+		binary.BigEndian.PutUint16(res, uint16(size))
+		res = append(res, e.scratch[addr:addr+size]...)
+		e.mux.Unlock()
+		e.reply(0xCD, res)
+	case 0xFA:
+		if len(data) < 2 {
+			return
+		}
+		status, value := e.handleObject(data[0:2], data[2:])
+		res := []byte{data[0], data[1], status}
+		res = append(res, value...)
+		e.reply(0xFA, res)
+	}
+}
+
+// handleObject executes a GTT2.5 object command and returns the status code
+// and the return values.
+func (e *Emulator) handleObject(method, args []byte) (byte, []byte) {
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	if len(e.failNext) > 0 {
+		status := e.failNext[0]
+		e.failNext = e.failNext[1:]
+		return status, nil
+	}
+
+	exists := func(id uint16) bool {
+		if !e.options.Strict {
+			return true
+		}
+		_, ok := e.objects[id]
+		return ok
+	}
+
+	switch {
+	// Create Object: type, id
+	case method[0] == 0x01 && method[1] == 0x00:
+		if len(args) < 4 {
+			return StatusFailed, nil
+		}
+		id := binary.BigEndian.Uint16(args[2:4])
+		if _, ok := e.objects[id]; ok {
+			return StatusObjectIDInUse, nil
+		}
+		e.objects[id] = [2]byte{args[0], args[1]}
+		return StatusSuccess, nil
+	// Destroy Object: id
+	case method[0] == 0x01 && method[1] == 0x01:
+		if len(args) < 2 {
+			return StatusFailed, nil
+		}
+		id := binary.BigEndian.Uint16(args[0:2])
+		if !exists(id) {
+			return StatusInvalidObjectID, nil
+		}
+		delete(e.objects, id)
+		return StatusSuccess, nil
+	// Property setters and getters: id, property, [value]
+	case method[0] == 0x01:
+		if len(args) < 4 {
+			return StatusFailed, nil
+		}
+		id := binary.BigEndian.Uint16(args[0:2])
+		if !exists(id) {
+			return StatusInvalidObjectID, nil
+		}
+		key := newPropKey(id, args[2:4])
+		value := args[4:]
+		switch method[1] {
+		// Set U8, U16, S16, S32, Bool
+		case 0x04, 0x06, 0x08, 0x0C, 0x0E:
+			size := map[byte]int{0x04: 1, 0x06: 2, 0x08: 2, 0x0C: 4, 0x0E: 1}[method[1]]
+			if len(value) < size {
+				return StatusInvalidType, nil
+			}
+			e.props[key] = append([]byte{}, value[:size]...)
+			return StatusSuccess, nil
+		// Set Text: encoding, length, UTF-16LE text
+		case 0x0A:
+			if len(value) < 3 {
+				return StatusInvalidType, nil
+			}
+			e.props[key] = append([]byte{}, value[1:]...)
+			return StatusSuccess, nil
+		// Get U8, U16, S16, S32, Bool
+		case 0x05, 0x07, 0x09, 0x0D, 0x0F:
+			size := map[byte]int{0x05: 1, 0x07: 2, 0x09: 2, 0x0D: 4, 0x0F: 1}[method[1]]
+			res := make([]byte, size)
+			copy(res, e.props[key])
+			return StatusSuccess, res
+		// Get Text: length, UTF-16LE text
+		case 0x0B:
+			res := e.props[key]
+			if len(res) < 2 {
+				res = []byte{0x00, 0x00}
+			}
+			return StatusSuccess, append([]byte{}, res...)
+		}
+		return StatusInvalidMethod, nil
+	// Set Focus, Begin/End Update, Bitmap Load/Capture, ObjectList Get: id
+	case method[0] == 0x02 && method[1] == 0x02,
+		method[0] == 0x1F && (method[1] == 0x00 || method[1] == 0x01),
+		method[0] == 0x0D && (method[1] == 0x00 || method[1] == 0x01),
+		method[0] == 0x1A && method[1] == 0x03:
+		if len(args) < 2 {
+			return StatusFailed, nil
+		}
+		id := binary.BigEndian.Uint16(args[0:2])
+		if method[0] != 0x0D && !exists(id) {
+			return StatusInvalidObjectID, nil
+		}
+		return StatusSuccess, nil
+	}
+	return StatusInvalidMethod, nil
+}
@@ -0,0 +1,97 @@
+package emulator
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// link is the shared state of the two ends of an emulated serial line.
+type link struct {
+	done chan struct{}
+	once sync.Once
+}
+
+func (l *link) close() {
+	l.once.Do(func() {
+		close(l.done)
+	})
+}
+
+// port is one end of an emulated serial line. Every Write is delivered as a
+// single chunk and a Read never returns bytes from two different chunks, the
+// way a serial read usually returns a whole packet written by the device.
+type port struct {
+	link    *link
+	in      chan []byte
+	out     chan []byte
+	timeout time.Duration
+	mux     sync.Mutex
+	rest    []byte
+}
+
+func newPorts(timeout time.Duration) (host, dev *port) {
+	l := &link{done: make(chan struct{})}
+	toDev := make(chan []byte, 256)
+	toHost := make(chan []byte, 256)
+	host = &port{link: l, in: toHost, out: toDev, timeout: timeout}
+	dev = &port{link: l, in: toDev, out: toHost}
+	return host, dev
+}
+
+// Read blocks until a chunk is available. With a timeout it returns 0, io.EOF
+// when nothing arrives in time, like a serial port opened with ReadTimeout.
+func (p *port) Read(b []byte) (int, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if len(p.rest) == 0 {
+		var timeout <-chan time.Time
+		if p.timeout > 0 {
+			timer := time.NewTimer(p.timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case <-p.link.done:
+			return 0, io.ErrClosedPipe
+		default:
+		}
+		select {
+		case chunk := <-p.in:
+			p.rest = chunk
+		case <-p.link.done:
+			return 0, io.ErrClosedPipe
+		case <-timeout:
+			return 0, io.EOF
+		}
+	}
+	n := copy(b, p.rest)
+	p.rest = p.rest[n:]
+	return n, nil
+}
+
+func (p *port) Write(b []byte) (int, error) {
+	if len(b) <= 0 {
+		return 0, nil
+	}
+	chunk := make([]byte, len(b))
+	copy(chunk, b)
+	select {
+	case <-p.link.done:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	select {
+	case p.out <- chunk:
+		return len(b), nil
+	case <-p.link.done:
+		return 0, io.ErrClosedPipe
+	}
+}
+
+// Close closes both ends of the line.
+func (p *port) Close() error {
+	p.link.close()
+	return nil
+}
@@ -1,9 +1,80 @@
 package gtt43a
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var ErrorDevClosed = errors.New("dev is closed")
 var ErrorDevNull = errors.New("dev is null")
 var ErrorDevTimeout = errors.New("dev timeout")
 var ErrorDevEmptyWrite = errors.New("write bytes in dev is empty")
 var ErrorDevEmptyRead = errors.New("read bytes in dev is empty")
+
+// StatusCode is the status byte of a GTT2.5 (0xFA) reply.
+type StatusCode byte
+
+const (
+	StatusSuccess         StatusCode = 0xFE
+	StatusFailed          StatusCode = 0xFD
+	StatusInvalidObjectID StatusCode = 0xFC
+	StatusInvalidProperty StatusCode = 0xFB
+	StatusInvalidType     StatusCode = 0xFA
+	StatusOutOfRange      StatusCode = 0xF9
+	StatusObjectIDInUse   StatusCode = 0xF8
+	StatusOutOfMemory     StatusCode = 0xF7
+	StatusInvalidMethod   StatusCode = 0xF6
+)
+
+func (c StatusCode) String() string {
+	switch c {
+	case StatusSuccess:
+		return "success"
+	case StatusFailed:
+		return "failed"
+	case StatusInvalidObjectID:
+		return "invalid object"
+	case StatusInvalidProperty:
+		return "invalid property"
+	case StatusInvalidType:
+		return "wrong type"
+	case StatusOutOfRange:
+		return "out of range"
+	case StatusObjectIDInUse:
+		return "object ID in use"
+	case StatusOutOfMemory:
+		return "out of memory"
+	case StatusInvalidMethod:
+		return "invalid method"
+	}
+	return fmt.Sprintf("unknown status [%X]", byte(c))
+}
+
+// StatusError is the error of a GTT2.5 request rejected by the device.
+// errors.Is matches it against the Err* sentinels by status code.
+type StatusError struct {
+	Code StatusCode
+	// SubCmd is the object method of the rejected request.
+	SubCmd []byte
+}
+
+func (e *StatusError) Error() string {
+	if len(e.SubCmd) <= 0 {
+		return fmt.Sprintf("status code: [%X] %s", byte(e.Code), e.Code)
+	}
+	return fmt.Sprintf("error in request [% X], status code: [%X] %s", e.SubCmd, byte(e.Code), e.Code)
+}
+
+func (e *StatusError) Is(target error) bool {
+	t, ok := target.(*StatusError)
+	return ok && t.Code == e.Code
+}
+
+var ErrFailed = &StatusError{Code: StatusFailed}
+var ErrObjectNotFound = &StatusError{Code: StatusInvalidObjectID}
+var ErrInvalidProperty = &StatusError{Code: StatusInvalidProperty}
+var ErrWrongType = &StatusError{Code: StatusInvalidType}
+var ErrOutOfRange = &StatusError{Code: StatusOutOfRange}
+var ErrObjectIDInUse = &StatusError{Code: StatusObjectIDInUse}
+var ErrOutOfMemory = &StatusError{Code: StatusOutOfMemory}
+var ErrInvalidMethod = &StatusError{Code: StatusInvalidMethod}
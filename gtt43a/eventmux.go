@@ -0,0 +1,154 @@
+package gtt43a
+
+import "sync"
+
+// propertyChange returns the payload of a property change event.
+func propertyChange(event *Event) (PropertyChangeEvent, bool) {
+	change, ok := event.Payload.(PropertyChangeEvent)
+	return change, ok && event.Type == GTT25BaseObjectOnPropertyChange
+}
+
+type route struct {
+	match  func(*Event) bool
+	handle func(*Event)
+}
+
+// EventMux calls the handlers registered by object ID for the events of a
+// display, from a single dispatcher goroutine: handlers run one at a time
+// and must not block.
+type EventMux struct {
+	mux    sync.Mutex
+	routes []*route
+	cancel func()
+	done   chan struct{}
+}
+
+// NewEventMux subscribes to every event of d and dispatches them until Close.
+func NewEventMux(d Display) *EventMux {
+	events, cancel := d.Subscribe(EventFilter{Buffer: 64})
+	x := &EventMux{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(x.done)
+		for event := range events {
+			x.dispatch(event)
+		}
+	}()
+	return x
+}
+
+// Close ends the subscription and waits for the running handler to return.
+func (x *EventMux) Close() {
+	x.cancel()
+	<-x.done
+}
+
+func (x *EventMux) dispatch(event *Event) {
+	x.mux.Lock()
+	handlers := make([]func(*Event), 0)
+	for _, r := range x.routes {
+		if r.match(event) {
+			handlers = append(handlers, r.handle)
+		}
+	}
+	x.mux.Unlock()
+	for _, handle := range handlers {
+		handle(event)
+	}
+}
+
+// add registers a route and returns the function that removes it.
+func (x *EventMux) add(r *route) func() {
+	x.mux.Lock()
+	defer x.mux.Unlock()
+	x.routes = append(x.routes, r)
+	return func() {
+		x.mux.Lock()
+		defer x.mux.Unlock()
+		for i, v := range x.routes {
+			if v == r {
+				x.routes = append(x.routes[:i:i], x.routes[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// OnButtonClick calls fn for the clicks of the button id. It returns the
+// function that removes the handler.
+func (x *EventMux) OnButtonClick(id int, fn func(Event)) func() {
+	return x.add(&route{
+		match: func(event *Event) bool {
+			return event.Type == ButtonClick && event.ObjId == uint16(id)
+		},
+		handle: func(event *Event) {
+			fn(*event)
+		},
+	})
+}
+
+// OnPropertyChange calls fn with the previous value (nil the first time)
+// and the new value of the property prop of the object id. It returns the
+// function that removes the handler.
+func (x *EventMux) OnPropertyChange(id int, prop GTT25PropertyType, fn func(old, new []byte)) func() {
+	var old []byte
+	return x.add(&route{
+		match: func(event *Event) bool {
+			change, ok := propertyChange(event)
+			return ok && event.ObjId == uint16(id) && string(change.Property) == string(prop)
+		},
+		handle: func(event *Event) {
+			change, _ := propertyChange(event)
+			fn(old, change.Value)
+			old = change.Value
+		},
+	})
+}
+
+// OnSliderChange calls fn with the new value of the slider id, reported as a
+// property change of SliderValue. It returns the function that removes the
+// handler.
+func (x *EventMux) OnSliderChange(id int, fn func(value int)) func() {
+	return x.add(&route{
+		match: func(event *Event) bool {
+			change, ok := propertyChange(event)
+			if !ok || event.ObjId != uint16(id) || string(change.Property) != string(SliderValue) {
+				return false
+			}
+			_, ok = change.Decoded.(int)
+			return ok
+		},
+		handle: func(event *Event) {
+			change, _ := propertyChange(event)
+			fn(change.Decoded.(int))
+		},
+	})
+}
+
+// OnRegionTouch calls fn for the touches of the region. It returns the
+// function that removes the handler.
+func (x *EventMux) OnRegionTouch(region int, fn func(TouchEvent)) func() {
+	return x.add(&route{
+		match: func(event *Event) bool {
+			return event.Type == RegionTouch && event.ObjId == uint16(region)
+		},
+		handle: func(event *Event) {
+			touch, _ := event.Payload.(TouchEvent)
+			fn(touch)
+		},
+	})
+}
+
+// OnTouch calls fn for the touch coordinate reports, sent when the touch
+// reporting style is TouchReportCoordinate or TouchReportRegionAndCoordinate.
+// It returns the function that removes the handler.
+func (x *EventMux) OnTouch(fn func(TouchEvent)) func() {
+	return x.add(&route{
+		match: func(event *Event) bool {
+			return event.Type == CoordinateTouch
+		},
+		handle: func(event *Event) {
+			touch, _ := event.Payload.(TouchEvent)
+			fn(touch)
+		},
+	})
+}
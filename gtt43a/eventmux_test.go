@@ -0,0 +1,132 @@
+package gtt43a
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventMux(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	x := NewEventMux(m)
+	defer x.Close()
+
+	clicks := make(chan Event, 4)
+	removeClick := x.OnButtonClick(5, func(evt Event) {
+		clicks <- evt
+	})
+	type change struct{ old, new []byte }
+	changes := make(chan change, 4)
+	x.OnPropertyChange(6, SliderValue, func(old, new []byte) {
+		changes <- change{old, new}
+	})
+	sliders := make(chan int, 4)
+	x.OnSliderChange(6, func(value int) {
+		sliders <- value
+	})
+	touches := make(chan TouchEvent, 4)
+	x.OnRegionTouch(2, func(touch TouchEvent) {
+		touches <- touch
+	})
+
+	e.InjectEvent(0x15, 4, []byte{0x01})
+	e.InjectEvent(0x15, 5, []byte{0x01})
+	e.InjectEvent(0x01, 6, []byte{0x0A, 0x08, 0x00, 0x10})
+	e.InjectEvent(0x01, 6, []byte{0x09, 0x06, 0x00, 0x11})
+	e.InjectEvent(0x01, 6, []byte{0x0A, 0x08, 0xFF, 0xE0})
+	e.InjectRegionTouch(1, 2)
+	e.InjectRegionTouch(0, 3)
+
+	select {
+	case evt := <-clicks:
+		if evt.ObjId != 5 {
+			t.Errorf("click: %+v", evt)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting click")
+	}
+	for _, want := range []change{{nil, []byte{0x00, 0x10}}, {[]byte{0x00, 0x10}, []byte{0xFF, 0xE0}}} {
+		select {
+		case got := <-changes:
+			if string(got.old) != string(want.old) || string(got.new) != string(want.new) {
+				t.Errorf("change: [% X] -> [% X], want [% X] -> [% X]", got.old, got.new, want.old, want.new)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting property change")
+		}
+	}
+	for _, want := range []int{16, -32} {
+		select {
+		case got := <-sliders:
+			if got != want {
+				t.Errorf("slider: %d, want %d", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting slider change")
+		}
+	}
+	select {
+	case touch := <-touches:
+		if touch.Region != 2 || touch.Kind != TouchPress {
+			t.Errorf("touch: %+v", touch)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting touch")
+	}
+
+	removeClick()
+	e.InjectEvent(0x15, 5, []byte{0x01})
+	e.InjectRegionTouch(0, 2)
+	select {
+	case <-touches:
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting touch")
+	}
+	select {
+	case evt := <-clicks:
+		t.Errorf("removed handler called: %+v", evt)
+	default:
+	}
+}
+
+func TestEventMuxTouch(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+	if err := m.ChangeTouchReporting(TouchReportRegionAndCoordinate); err != nil {
+		t.Fatalf("change touch reporting: %s", err)
+	}
+	if style, err := m.GetTouchReporting(); err != nil || style != TouchReportRegionAndCoordinate {
+		t.Fatalf("touch reporting: %s, %v", style, err)
+	}
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	x := NewEventMux(m)
+	defer x.Close()
+
+	touches := make(chan TouchEvent, 4)
+	x.OnTouch(func(touch TouchEvent) {
+		touches <- touch
+	})
+
+	e.InjectRegionTouch(1, 2)
+	e.InjectTouch(1, 10, 200)
+	e.InjectTouch(2, 300, 210)
+	e.InjectTouch(0, 450, 215)
+
+	for _, want := range []TouchEvent{
+		{Kind: TouchPress, X: 10, Y: 200, Region: -1},
+		{Kind: TouchDrag, X: 300, Y: 210, Region: -1},
+		{Kind: TouchRelease, X: 450, Y: 215, Region: -1},
+	} {
+		select {
+		case touch := <-touches:
+			if touch != want {
+				t.Errorf("touch: %+v, want %+v", touch, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting touch")
+		}
+	}
+}
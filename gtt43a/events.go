@@ -7,8 +7,6 @@ import (
 	// _ "bytes"
 	"encoding/binary"
 	"fmt"
-	"log"
-	"time"
 )
 
 type EventType int
@@ -18,69 +16,205 @@ const (
 	GTT25VisualObjectOnKey
 	ButtonClick
 	RegionTouch
+	// CoordinateTouch is a touch report with coordinates, see TouchEvent.
+	CoordinateTouch
+	// Unknown is an object event with an ID not decoded, see UnknownEvent.
+	Unknown
 )
 
+// GTT2.5 event IDs (little endian in the 0xEB frames) decoded by
+// parseEvent, the ones Events() always handled. A slider reports its value
+// as a property change of SliderValue, decoded like every registered
+// property (see PropertyChangeEvent and EventMux.OnSliderChange). Toggle
+// state and list selection have no documented event or property ID: they
+// come as OnPropertyChange with the raw value, or as UnknownEvent.
+const (
+	eventPropertyChange uint16 = 0x01
+	eventKey            uint16 = 0x02
+	eventButtonClick    uint16 = 0x15
+)
+
+func (t EventType) String() string {
+	switch t {
+	case GTT25BaseObjectOnPropertyChange:
+		return "OnPropertyChange"
+	case GTT25VisualObjectOnKey:
+		return "OnKey"
+	case ButtonClick:
+		return "ButtonClick"
+	case RegionTouch:
+		return "RegionTouch"
+	case CoordinateTouch:
+		return "CoordinateTouch"
+	case Unknown:
+		return "Unknown"
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
 type Event struct {
 	Type  EventType
 	ObjId uint16
+	// Value is the raw payload of the event.
 	Value []byte
+	// Payload is the decoded Value: PropertyChangeEvent, KeyEvent,
+	// ButtonClickEvent, TouchEvent or UnknownEvent. nil if Value is too
+	// short.
+	Payload interface{}
+}
+
+// PropertyChangeEvent is the payload of GTT25BaseObjectOnPropertyChange:
+// the property (2 bytes) and its new raw value.
+type PropertyChangeEvent struct {
+	Property GTT25PropertyType
+	Value    []byte
+	// Decoded is Value decoded with the type of Property in the registry:
+	// int for the integer and colour types, string for Text, bool for
+	// Bool. nil if Property is not in the registry or Value is too short.
+	Decoded interface{}
+}
+
+// KeyState of a key event.
+type KeyState int
+
+const (
+	KeyUp KeyState = iota
+	KeyDown
+)
+
+// KeyEvent is the payload of GTT25VisualObjectOnKey: key code, state.
+type KeyEvent struct {
+	Key   byte
+	State KeyState
+}
+
+// ButtonClickEvent is the payload of ButtonClick: 1 press, 0 release.
+type ButtonClickEvent struct {
+	Pressed bool
+}
+
+// UnknownEvent is the payload of the object events with an ID not decoded.
+type UnknownEvent struct {
+	ID      uint16
+	Payload []byte
+}
+
+// EventFromFrame decodes an event frame (0xEB object event, 0x86 coordinate
+// touch or 0x87 region touch) as Events() does. It returns false for any
+// other frame, and for event frames shorter than their header: 4 bytes for
+// 0xEB, 5 for 0x86, 2 for 0x87. Longer reports are accepted, the bytes
+// after the header are ignored by the touch reports.
+func EventFromFrame(frame Frame) (*Event, bool) {
+	return parseEvent(append([]byte{frame.Cmd}, frame.Payload...))
+}
+
+// parseEvent decodes an event as queued by the listener: cmd + payload.
+func parseEvent(v []byte) (*Event, bool) {
+	switch {
+	case len(v) >= 5 && byte(0xEB) == v[0]:
+		evnt := binary.LittleEndian.Uint16(v[1:3])
+		event := &Event{
+			ObjId: binary.BigEndian.Uint16(v[3:5]),
+			Value: v[5:],
+		}
+		data := event.Value
+		switch evnt {
+		case eventPropertyChange:
+			event.Type = GTT25BaseObjectOnPropertyChange
+			if len(data) >= 2 {
+				change := PropertyChangeEvent{Property: GTT25PropertyType(data[:2]), Value: data[2:]}
+				change.Decoded = decodePropertyValue(change.Property, change.Value)
+				event.Payload = change
+			}
+		case eventKey:
+			event.Type = GTT25VisualObjectOnKey
+			if len(data) >= 2 {
+				event.Payload = KeyEvent{Key: data[0], State: KeyState(data[1])}
+			}
+		case eventButtonClick:
+			event.Type = ButtonClick
+			if len(data) >= 1 {
+				event.Payload = ButtonClickEvent{Pressed: data[0] != 0}
+			}
+		default:
+			event.Type = Unknown
+			event.Payload = UnknownEvent{ID: evnt, Payload: data}
+		}
+		return event, true
+	case len(v) >= 3 && byte(0x87) == v[0]:
+		return &Event{
+			Type:    RegionTouch,
+			ObjId:   uint16(v[2]),
+			Value:   v[1:2],
+			Payload: TouchEvent{Kind: TouchKind(v[1]), Region: int(v[2])},
+		}, true
+	case len(v) >= 6 && byte(0x86) == v[0]:
+		return &Event{
+			Type:  CoordinateTouch,
+			Value: v[1:6],
+			Payload: TouchEvent{
+				Kind:   TouchKind(v[1]),
+				X:      int(binary.BigEndian.Uint16(v[2:4])),
+				Y:      int(binary.BigEndian.Uint16(v[4:6])),
+				Region: -1,
+			},
+		}, true
+	}
+	return nil, false
+}
+
+// decodePropertyValue decodes the value of a property change with the type
+// of the property in the registry, nil if it is not registered or value is
+// too short.
+func decodePropertyValue(prpType GTT25PropertyType, value []byte) interface{} {
+	info, ok := LookupProperty(prpType)
+	if !ok {
+		return nil
+	}
+	res := &Response{Payload: value}
+	var v interface{}
+	var err error
+	switch info.Type {
+	case PropertyU8, PropertyColour:
+		var u uint8
+		u, err = res.Uint8()
+		v = int(u)
+	case PropertyU16:
+		var u uint16
+		u, err = res.Uint16()
+		v = int(u)
+	case PropertyS16:
+		var s int16
+		s, err = res.Int16()
+		v = int(s)
+	case PropertyS32:
+		var s int32
+		s, err = res.Int32()
+		v = int(s)
+	case PropertyBool:
+		v, err = res.Bool()
+	case PropertyText:
+		v, err = res.Text()
+	}
+	if err != nil {
+		return nil
+	}
+	return v
 }
 
 //ListenEvents is a go rutine that listening serial port to detect event messages
-//Return channel with event messages (Event struct)
+//Return channel with event messages (Event struct), closed when the listener
+//stops. It is a subscription to every event, see Subscribe.
 func (m *display) Events() (chan *Event, error) {
-	if m.status != LISTEN {
+	l := m.currentListener()
+	if l == nil {
 		return nil, fmt.Errorf("error, display don't be listenning. Execute Listen() before wait events")
 	}
-	mc := make(chan *Event, 0)
+	sub, cancel := m.events.subscribe(EventFilter{})
 	go func() {
-		defer close(mc)
-		defer fmt.Println("STOP listen events")
-		for v := range m.chEvent {
-			log.Printf("read Event: [% X]\n", v)
-			objID := uint16(0)
-			var data []byte
-			var evnT EventType
-			switch {
-			case byte(0xEB) == v[0]:
-				evnt := binary.LittleEndian.Uint16(v[1:3])
-				switch evnt {
-				case 0x01:
-					evnT = GTT25BaseObjectOnPropertyChange
-				case 0x02:
-					evnT = GTT25VisualObjectOnKey
-				case 0x15:
-					evnT = ButtonClick
-				default:
-					continue
-				}
-				objID = binary.BigEndian.Uint16(v[3:5])
-				data = v[5:]
-			case byte(0x87) == v[0]:
-				switch {
-				case len(v) == 3:
-					evnT = RegionTouch
-				default:
-					continue
-				}
-				objID = uint16(v[2])
-				data = v[1:2]
-			default:
-				continue
-			}
-
-			// go func(evnT EventType, objId uint16, data []byte) {
-			event := &Event{
-				evnT,
-				objID,
-				data,
-			}
-			select {
-			case mc <- event:
-			case <-time.After(timeoutRead):
-			}
-			// }(evnT, objID, data)
-		}
+		<-l.done
+		m.logger().Debug("stop events")
+		cancel()
 	}()
-	return mc, nil
+	return sub.ch, nil
 }
@@ -0,0 +1,71 @@
+package gtt43a
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEventFromFrame(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   Frame
+		typ     EventType
+		objID   uint16
+		payload interface{}
+	}{
+		{"property change", Frame{Cmd: 0xEB, Payload: []byte{0x01, 0x00, 0x00, 0x04, 0x02, 0x03, 0x01, 0x2A}},
+			GTT25BaseObjectOnPropertyChange, 4, PropertyChangeEvent{Property: Width, Value: []byte{0x01, 0x2A}, Decoded: 0x012A}},
+		{"bool property change", Frame{Cmd: 0xEB, Payload: []byte{0x01, 0x00, 0x00, 0x04, 0x02, 0x07, 0x01}},
+			GTT25BaseObjectOnPropertyChange, 4, PropertyChangeEvent{Property: Enabled, Value: []byte{0x01}, Decoded: true}},
+		{"text property change", Frame{Cmd: 0xEB, Payload: []byte{0x01, 0x00, 0x00, 0x04, 0x09, 0x06, 0x00, 0x04, 'o', 0x00, 'k', 0x00}},
+			GTT25BaseObjectOnPropertyChange, 4, PropertyChangeEvent{Property: LabelText, Value: []byte{0x00, 0x04, 'o', 0x00, 'k', 0x00}, Decoded: "ok"}},
+		{"unregistered property change", Frame{Cmd: 0xEB, Payload: []byte{0x01, 0x00, 0x00, 0x04, 0x7F, 0x00, 0x05}},
+			GTT25BaseObjectOnPropertyChange, 4, PropertyChangeEvent{Property: GTT25PropertyType{0x7F, 0x00}, Value: []byte{0x05}}},
+		{"slider value change", Frame{Cmd: 0xEB, Payload: []byte{0x01, 0x00, 0x00, 0x06, 0x0A, 0x08, 0xFF, 0xFE}},
+			GTT25BaseObjectOnPropertyChange, 6, PropertyChangeEvent{Property: SliderValue, Value: []byte{0xFF, 0xFE}, Decoded: -2}},
+		{"short slider value change", Frame{Cmd: 0xEB, Payload: []byte{0x01, 0x00, 0x00, 0x06, 0x0A, 0x08, 0xFF}},
+			GTT25BaseObjectOnPropertyChange, 6, PropertyChangeEvent{Property: SliderValue, Value: []byte{0xFF}}},
+		{"key", Frame{Cmd: 0xEB, Payload: []byte{0x02, 0x00, 0x00, 0x04, 0x0D, 0x01}},
+			GTT25VisualObjectOnKey, 4, KeyEvent{Key: 0x0D, State: KeyDown}},
+		{"button release", Frame{Cmd: 0xEB, Payload: []byte{0x15, 0x00, 0x00, 0x05, 0x00}},
+			ButtonClick, 5, ButtonClickEvent{Pressed: false}},
+		{"unknown", Frame{Cmd: 0xEB, Payload: []byte{0x30, 0x01, 0x00, 0x09, 0xAA}},
+			Unknown, 9, UnknownEvent{ID: 0x0130, Payload: []byte{0xAA}}},
+		{"unverified slider event", Frame{Cmd: 0xEB, Payload: []byte{0x0A, 0x00, 0x00, 0x06, 0xFF, 0xFE}},
+			Unknown, 6, UnknownEvent{ID: 0x0A, Payload: []byte{0xFF, 0xFE}}},
+		{"short payload", Frame{Cmd: 0xEB, Payload: []byte{0x02, 0x00, 0x00, 0x06, 0x0D}},
+			GTT25VisualObjectOnKey, 6, nil},
+		{"region touch", Frame{Cmd: 0x87, Payload: []byte{0x02, 0x03}},
+			RegionTouch, 3, TouchEvent{Kind: TouchDrag, Region: 3}},
+		{"long region touch", Frame{Cmd: 0x87, Payload: []byte{0x01, 0x04, 0x00}},
+			RegionTouch, 4, TouchEvent{Kind: TouchPress, Region: 4}},
+		{"long coordinate touch", Frame{Cmd: 0x86, Payload: []byte{0x00, 0x00, 0x10, 0x00, 0x20, 0x00}},
+			CoordinateTouch, 0, TouchEvent{Kind: TouchRelease, X: 16, Y: 32, Region: -1}},
+		{"coordinate touch", Frame{Cmd: 0x86, Payload: []byte{0x01, 0x01, 0x2C, 0x00, 0xC8}},
+			CoordinateTouch, 0, TouchEvent{Kind: TouchPress, X: 300, Y: 200, Region: -1}},
+	}
+	for _, tt := range tests {
+		event, ok := EventFromFrame(tt.frame)
+		if !ok {
+			t.Errorf("%s: not an event", tt.name)
+			continue
+		}
+		if event.Type != tt.typ || event.ObjId != tt.objID {
+			t.Errorf("%s: got %s obj=%d, want %s obj=%d", tt.name, event.Type, event.ObjId, tt.typ, tt.objID)
+		}
+		if !reflect.DeepEqual(event.Payload, tt.payload) {
+			t.Errorf("%s: payload %#v, want %#v", tt.name, event.Payload, tt.payload)
+		}
+	}
+
+	for _, frame := range []Frame{
+		{Cmd: 0xFA, Payload: []byte{0x01, 0x04, 0xFE}},
+		{Cmd: 0xEB, Payload: []byte{0x15, 0x00, 0x00}},
+		{Cmd: 0x86, Payload: []byte{0x01, 0x00, 0x10, 0x00}},
+		{Cmd: 0x87, Payload: []byte{0x01}},
+	} {
+		if _, ok := EventFromFrame(frame); ok {
+			t.Errorf("frame %v decoded as an event", frame)
+		}
+	}
+}
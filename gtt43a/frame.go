@@ -0,0 +1,125 @@
+package gtt43a
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+)
+
+// Frame is a packet sent by the device:
+// 0xFC, Cmd, length of Payload (2 bytes, big endian), Payload.
+type Frame struct {
+	Cmd     byte
+	Payload []byte
+}
+
+// Bytes encodes the frame as it is sent on the wire.
+func (f Frame) Bytes() []byte {
+	data := []byte{0xFC, f.Cmd, 0x00, 0x00}
+	binary.BigEndian.PutUint16(data[2:4], uint16(len(f.Payload)))
+	return append(data, f.Payload...)
+}
+
+// MaxFramePayload is the longest payload accepted by FrameDecoder: the
+// largest reply is a ReadScratch of the whole 4 KiB scratch memory, whose
+// payload is the 2 byte size followed by the data.
+const MaxFramePayload = 4096 + 2
+
+// FrameDecoder accumulates the bytes read from the device and splits them in
+// complete frames. Bytes before a 0xFC header are discarded, so the decoder
+// resynchronises after garbage or a lost packet start. A header with 0xFC as
+// command or a length over MaxFramePayload can only be a stray 0xFC: its first
+// byte is dropped and the scan goes on from the next 0xFC. A header with a
+// plausible length is waited for until Resync gives it up.
+type FrameDecoder struct {
+	buf []byte
+	// since is when the frame being assembled started to arrive.
+	since time.Time
+}
+
+// Write appends data read from the device. It never fails.
+func (d *FrameDecoder) Write(p []byte) (int, error) {
+	if len(d.buf) == 0 {
+		d.since = time.Now()
+	}
+	d.buf = append(d.buf, p...)
+	return len(p), nil
+}
+
+// Next returns the next complete frame, false if more bytes are needed.
+func (d *FrameDecoder) Next() (Frame, bool) {
+	for {
+		idx := bytes.IndexByte(d.buf, 0xFC)
+		if idx < 0 {
+			d.buf = d.buf[:0]
+			return Frame{}, false
+		}
+		d.buf = d.buf[idx:]
+		if len(d.buf) >= 2 && d.buf[1] == 0xFC {
+			d.buf = d.buf[1:]
+			continue
+		}
+		if len(d.buf) < 4 {
+			return Frame{}, false
+		}
+		lenValue := int(binary.BigEndian.Uint16(d.buf[2:4]))
+		if lenValue > MaxFramePayload {
+			d.buf = d.buf[1:]
+			continue
+		}
+		if len(d.buf) < lenValue+4 {
+			return Frame{}, false
+		}
+		frame := Frame{
+			Cmd:     d.buf[1],
+			Payload: append([]byte{}, d.buf[4:lenValue+4]...),
+		}
+		d.buf = d.buf[lenValue+4:]
+		d.since = time.Now()
+		return frame, true
+	}
+}
+
+// Decode writes p and returns every frame completed by it.
+func (d *FrameDecoder) Decode(p []byte) []Frame {
+	d.Write(p)
+	frames := make([]Frame, 0)
+	for {
+		frame, ok := d.Next()
+		if !ok {
+			return frames
+		}
+		frames = append(frames, frame)
+	}
+}
+
+// Buffered returns the number of bytes waiting to complete a frame.
+func (d *FrameDecoder) Buffered() int {
+	return len(d.buf)
+}
+
+// Age returns how long the buffered bytes have been waiting to complete a
+// frame, 0 if nothing is buffered.
+func (d *FrameDecoder) Age() time.Duration {
+	if len(d.buf) == 0 {
+		return 0
+	}
+	return time.Since(d.since)
+}
+
+// Resync gives up the frame being assembled, when the rest of it is not
+// coming: its 0xFC is dropped and the next call to Next scans the bytes after
+// it for the next 0xFC. Line noise like FC 12 0F 00 would otherwise wait for
+// 0x0F00 bytes and swallow every frame after it.
+func (d *FrameDecoder) Resync() {
+	if len(d.buf) == 0 {
+		return
+	}
+	d.buf = d.buf[1:]
+	d.since = time.Now()
+}
+
+// Reset discards the buffered bytes.
+func (d *FrameDecoder) Reset() {
+	d.buf = d.buf[:0]
+}
@@ -0,0 +1,52 @@
+//go:build go1.18
+// +build go1.18
+
+package gtt43a
+
+import (
+	"bytes"
+	"testing"
+)
+
+func FuzzFrameDecoder(f *testing.F) {
+	f.Add([]byte{0xFC, 0x00, 0x00, 0x02, 0x01, 0x02}, uint8(3))
+	f.Add([]byte{0xFC, 0xEB, 0x00, 0x05, 0x15, 0x00, 0x00, 0x05, 0x01, 0xFC, 0x87, 0x00, 0x02, 0x01, 0x07}, uint8(5))
+	f.Add([]byte{0x00, 0xFC, 0xFC, 0x00, 0x00, 0xFE}, uint8(1))
+	f.Add([]byte{0x01, 0x06, 0xFC, 0xFC, 0xFF, 0x00, 0x01, 0x33, 0xFC, 0x00, 0x00, 0x02, 0x01, 0x02}, uint8(2))
+	f.Add([]byte{0xFC, 0x12, 0x0F, 0x00, 0xFC, 0xFB, 0x00, 0x00, 0xFC, 0x00, 0x00, 0x02, 0x01, 0x02}, uint8(3))
+	f.Fuzz(func(t *testing.T, data []byte, split uint8) {
+		whole := (&FrameDecoder{}).Decode(data)
+
+		// The same stream read in chunks must give the same frames.
+		decoder := &FrameDecoder{}
+		chunked := make([]Frame, 0)
+		size := int(split) + 1
+		for i := 0; i < len(data); i += size {
+			end := i + size
+			if end > len(data) {
+				end = len(data)
+			}
+			chunked = append(chunked, decoder.Decode(data[i:end])...)
+		}
+		if len(whole) != len(chunked) {
+			t.Fatalf("frames: %d in one read, %d in chunks", len(whole), len(chunked))
+		}
+		for i := range whole {
+			if whole[i].Cmd != chunked[i].Cmd || !bytes.Equal(whole[i].Payload, chunked[i].Payload) {
+				t.Fatalf("frame %d: %v != %v", i, whole[i], chunked[i])
+			}
+			// Every frame is in the stream and decodes back to itself.
+			raw := whole[i].Bytes()
+			if !bytes.Contains(data, raw) {
+				t.Fatalf("frame %d [% X] not in input", i, raw)
+			}
+			again := (&FrameDecoder{}).Decode(raw)
+			if len(again) != 1 || again[0].Cmd != whole[i].Cmd || !bytes.Equal(again[0].Payload, whole[i].Payload) {
+				t.Fatalf("frame %d does not round trip: %v", i, again)
+			}
+		}
+		if decoder.Buffered() > len(data) {
+			t.Fatalf("buffered %d bytes of %d", decoder.Buffered(), len(data))
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package gtt43a
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestFrameDecoder(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks [][]byte
+		want   []Frame
+		rest   int
+		// resync gives up the pending frame after the chunks.
+		resync bool
+	}{
+		{
+			name:   "single frame",
+			chunks: [][]byte{{0xFC, 0x00, 0x00, 0x02, 0x01, 0x02}},
+			want:   []Frame{{0x00, []byte{0x01, 0x02}}},
+		},
+		{
+			name:   "empty payload",
+			chunks: [][]byte{{0xFC, 0xFB, 0x00, 0x00}},
+			want:   []Frame{{0xFB, []byte{}}},
+		},
+		{
+			name:   "split header",
+			chunks: [][]byte{{0xFC, 0xFA}, {0x00, 0x03, 0x01, 0x06, 0xFE}},
+			want:   []Frame{{0xFA, []byte{0x01, 0x06, 0xFE}}},
+		},
+		{
+			name:   "split payload",
+			chunks: [][]byte{{0xFC, 0xFF, 0x00, 0x04, 0x01}, {0x02, 0x03}, {0x04}},
+			want:   []Frame{{0xFF, []byte{0x01, 0x02, 0x03, 0x04}}},
+		},
+		{
+			name: "two frames in one read",
+			chunks: [][]byte{{
+				0xFC, 0xEB, 0x00, 0x05, 0x15, 0x00, 0x00, 0x05, 0x01,
+				0xFC, 0x87, 0x00, 0x02, 0x01, 0x07,
+			}},
+			want: []Frame{
+				{0xEB, []byte{0x15, 0x00, 0x00, 0x05, 0x01}},
+				{0x87, []byte{0x01, 0x07}},
+			},
+		},
+		{
+			name:   "second frame truncated",
+			chunks: [][]byte{{0xFC, 0xFB, 0x00, 0x00, 0xFC, 0x00, 0x00, 0x02, 0x01}},
+			want:   []Frame{{0xFB, []byte{}}},
+			rest:   5,
+		},
+		{
+			name:   "second frame completed later",
+			chunks: [][]byte{{0xFC, 0xFB, 0x00, 0x00, 0xFC, 0x00, 0x00, 0x02, 0x01}, {0x02}},
+			want:   []Frame{{0xFB, []byte{}}, {0x00, []byte{0x01, 0x02}}},
+		},
+		{
+			name:   "resync after garbage",
+			chunks: [][]byte{{0x00, 0x41, 0x42}, {0xFE, 0xFC, 0xFF, 0x00, 0x01, 0x33}},
+			want:   []Frame{{0xFF, []byte{0x33}}},
+		},
+		{
+			name:   "resync on stray 0xFC",
+			chunks: [][]byte{{0x01, 0x06, 0xFC, 0xFC, 0xFF, 0x00, 0x01, 0x33, 0xFC, 0x00, 0x00, 0x02, 0x01, 0x02}},
+			want:   []Frame{{0xFF, []byte{0x33}}, {0x00, []byte{0x01, 0x02}}},
+		},
+		{
+			name:   "resync on implausible length",
+			chunks: [][]byte{{0xFC, 0x00, 0xFF}, {0x00, 0x00, 0xFC, 0xFB, 0x00, 0x00}},
+			want:   []Frame{{0xFB, []byte{}}},
+		},
+		{
+			name:   "glitch header waits for its length",
+			chunks: [][]byte{{0xFC, 0x12, 0x0F, 0x00}, {0xFC, 0xFB, 0x00, 0x00}, {0xFC, 0x00, 0x00, 0x02, 0x01, 0x02}},
+			want:   []Frame{},
+			rest:   14,
+		},
+		{
+			name:   "resync after glitch header",
+			chunks: [][]byte{{0xFC, 0x12, 0x0F, 0x00}, {0xFC, 0xFB, 0x00, 0x00}, {0xFC, 0x00, 0x00, 0x02, 0x01, 0x02}},
+			want:   []Frame{{0xFB, []byte{}}, {0x00, []byte{0x01, 0x02}}},
+			resync: true,
+		},
+		{
+			name:   "only garbage",
+			chunks: [][]byte{{0x01, 0x02, 0x03}},
+			want:   []Frame{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := &FrameDecoder{}
+			got := make([]Frame, 0)
+			for _, chunk := range tt.chunks {
+				got = append(got, decoder.Decode(chunk)...)
+			}
+			if tt.resync {
+				decoder.Resync()
+				got = append(got, decoder.Decode(nil)...)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("frames: %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Cmd != tt.want[i].Cmd || !bytes.Equal(got[i].Payload, tt.want[i].Payload) {
+					t.Errorf("frame %d: %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+			if decoder.Buffered() != tt.rest {
+				t.Errorf("buffered: %d, want %d", decoder.Buffered(), tt.rest)
+			}
+		})
+	}
+}
+
+func TestFrameDecoderAge(t *testing.T) {
+	decoder := &FrameDecoder{}
+	if decoder.Age() != 0 {
+		t.Errorf("empty decoder age: %s", decoder.Age())
+	}
+	decoder.Write([]byte{0xFC, 0x12, 0x0F, 0x00})
+	time.Sleep(20 * time.Millisecond)
+	if age := decoder.Age(); age < 20*time.Millisecond {
+		t.Errorf("age: %s", age)
+	}
+	decoder.Resync()
+	if age := decoder.Age(); age >= 20*time.Millisecond {
+		t.Errorf("age after resync: %s", age)
+	}
+}
+
+func TestFrameBytes(t *testing.T) {
+	frame := Frame{Cmd: 0xFA, Payload: []byte{0x01, 0x07, 0xFE, 0x00, 0x10}}
+	want := []byte{0xFC, 0xFA, 0x00, 0x05, 0x01, 0x07, 0xFE, 0x00, 0x10}
+	if !bytes.Equal(frame.Bytes(), want) {
+		t.Errorf("bytes: [% X], want [% X]", frame.Bytes(), want)
+	}
+}
@@ -0,0 +1,111 @@
+package gtt43a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrorNotListening is the error of the asynchronous requests sent when the
+// display is not listening.
+var ErrorNotListening = errors.New("display is not listening, execute Listen() before async requests")
+
+// Future is the reply of an asynchronous request.
+type Future struct {
+	done chan struct{}
+	res  *Response
+	err  error
+}
+
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+func (f *Future) complete(res *Response, err error) *Future {
+	f.res = res
+	f.err = err
+	close(f.done)
+	return f
+}
+
+// Done is closed when the reply is received or the request fails.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits the reply. For GTT2.5 object commands (0xFA) a status other
+// than success is returned as a *StatusError, with the reply.
+func (f *Future) Wait() (*Response, error) {
+	<-f.done
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.res.Cmd == 0xFA {
+		return f.res, f.res.Err()
+	}
+	return f.res, nil
+}
+
+// WaitContext is Wait bounded by ctx. The request is not cancelled when ctx
+// is done, only the wait.
+func (f *Future) WaitContext(ctx context.Context) (*Response, error) {
+	select {
+	case <-f.done:
+		return f.Wait()
+	case <-ctx.Done():
+		return nil, contextError(ctx)
+	}
+}
+
+// WaitAll waits every future, in order, and returns the first error.
+func WaitAll(futures ...*Future) error {
+	var first error
+	for _, f := range futures {
+		if _, err := f.Wait(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Send a command to display device without waiting the response: the reply
+// is collected by the returned Future. Many requests can be pipelined back to
+// back, each one times out after the response timeout. Only in listen mode.
+func (m *display) SendCmdAsync(cmd int, data []byte) *Future {
+	dat1 := []byte{0xFE, byte(cmd)}
+	if data != nil {
+		dat1 = append(dat1, data...)
+	}
+	return m.SendRecvAsync(dat1)
+}
+
+// SendRecvAsync is SendCmdAsync for a complete command, as built by the Apdu*
+// functions.
+func (m *display) SendRecvAsync(data []byte) *Future {
+	f := newFuture()
+	key, ok := requestKey(data)
+	if !ok {
+		return f.complete(nil, fmt.Errorf("bad command: [% X]", data))
+	}
+
+	m.wmux.Lock()
+	l := m.currentListener()
+	if l == nil {
+		m.wmux.Unlock()
+		return f.complete(nil, ErrorNotListening)
+	}
+	p := l.pending.add(key, data, m.responseTimeout())
+	err := m.send(data)
+	m.wmux.Unlock()
+	if err != nil {
+		l.pending.remove(p)
+		return f.complete(nil, err)
+	}
+
+	go func() {
+		ctx, cancel := m.requestContext(context.Background())
+		defer cancel()
+		f.complete(m.waitPending(ctx, l, p))
+	}()
+	return f
+}
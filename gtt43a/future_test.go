@@ -0,0 +1,52 @@
+package gtt43a
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
+)
+
+func TestSendRecvAsyncPipeline(t *testing.T) {
+	m, e := newEmulatedDisplay(t, &emulator.Options{Strict: true})
+	for id := 1; id <= 30; id++ {
+		e.AddObject(id, ObjectType_VisualBitmap)
+	}
+
+	if _, err := m.SendRecvAsync(ApduSetPropertyValueU16(1, Width, 1)).Wait(); !errors.Is(err, ErrorNotListening) {
+		t.Fatalf("expected ErrorNotListening, got %v", err)
+	}
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+
+	futures := make([]*Future, 0)
+	for id := 1; id <= 30; id++ {
+		futures = append(futures, m.SendRecvAsync(ApduSetPropertyValueU16(id, Width, id*100)))
+		futures = append(futures, m.SendRecvAsync(ApduSetPropertyText(id, LabelText, "label")))
+	}
+	if err := WaitAll(futures...); err != nil {
+		t.Fatalf("pipeline: %s", err)
+	}
+	for id := 1; id <= 30; id++ {
+		if v := e.Property(id, Width); !bytes.Equal(v, []byte{byte(id * 100 >> 8), byte(id * 100)}) {
+			t.Errorf("object %d: width [% X]", id, v)
+		}
+	}
+
+	// the status of every reply is checked
+	ok := m.SendCmdAsync(0xFA, ApduSetPropertyValueU16(1, Width, 5)[2:])
+	missing := m.SendRecvAsync(ApduSetPropertyValueU16(99, Width, 5))
+	echo := m.SendCmdAsync(0xFF, []byte("hi"))
+	if _, err := missing.Wait(); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("expected ErrObjectNotFound, got %v", err)
+	}
+	if _, err := ok.Wait(); err != nil {
+		t.Errorf("set: %s", err)
+	}
+	<-echo.Done()
+	if res, err := echo.Wait(); err != nil || string(res.Payload) != "hi" {
+		t.Errorf("echo: %v, %v", res, err)
+	}
+}
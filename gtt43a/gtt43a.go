@@ -6,14 +6,11 @@ Package to send commands and recieve response to and from gtt43a device.
 package gtt43a
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	_ "os"
 	"sync"
 	"time"
@@ -22,11 +19,46 @@ import (
 )
 
 type PortOptions struct {
-	Port        string
-	Baud        int
+	Port string
+	Baud int
+	// ReadTimeout of the serial port: a read returning io.EOF after it is a
+	// timeout. With 0, io.EOF is a read error, the end of the transport.
 	ReadTimeout time.Duration
+	// ResponseTimeout is the time to wait for every packet of a response,
+	// 0 means 600 ms.
+	ResponseTimeout time.Duration
+	// MaxListenErrors is the number of consecutive read errors that stops the
+	// listener, 0 means 5.
+	MaxListenErrors int
+	// Retry, if not nil, is applied to idempotent requests.
+	Retry *RetryPolicy
+	// Logger receives the traffic and the errors of the display, nil means
+	// silent.
+	Logger Logger
+	// HeartbeatInterval, if not 0, is the period of the Echo requests sent
+	// while listening to check that the panel answers.
+	HeartbeatInterval time.Duration
+	// HeartbeatMisses is the number of consecutive heartbeats without reply
+	// that turn the state to UNRESPONSIVE, 0 means 3.
+	HeartbeatMisses int
 }
 
+// RetryPolicy repeats the idempotent requests (Version, Echo,
+// GetPropertyValue*, GetPropertyText, ReadScratch, ...) that fail with
+// ErrorDevTimeout.
+type RetryPolicy struct {
+	// Count is the number of retries after the first attempt.
+	Count int
+	// Backoff is the wait before the first retry, doubled on every retry.
+	Backoff time.Duration
+}
+
+// Display is a GTT display. Every request that waits for a reply has a
+// ...Context variant bounded by ctx, except the legacy commands
+// (UpdateBargraphValue, SetLabelBackgroundColour, CreateLabelLegacy and the
+// *Legacy/*Legcay methods), which wait for the response timeout: send them
+// with SendRecvCmdContext to bound them. The commands without reply (Send,
+// SendCmd, ClrScreen, ...) only write to the port.
 type Display interface {
 	Open() error
 	Close() error
@@ -34,16 +66,24 @@ type Display interface {
 	Text(string) error
 	FontSize(int) error
 	Send([]byte) error
-	recv() ([]byte, error)
-	Recv() ([]byte, error)
-	SendRecv([]byte) ([]byte, error)
+	recv(ctx context.Context) ([]byte, error)
+	Recv() (*Response, error)
+	SendRecv([]byte) (*Response, error)
 	Echo([]byte) ([]byte, error)
 	Version() ([]byte, error)
-	SendRecvCmd(int, []byte) ([]byte, error)
+	SendRecvCmd(int, []byte) (*Response, error)
+	RecvContext(ctx context.Context) (*Response, error)
+	SendRecvContext(ctx context.Context, data []byte) (*Response, error)
+	SendRecvCmdContext(ctx context.Context, cmd int, data []byte) (*Response, error)
+	EchoContext(ctx context.Context, data []byte) ([]byte, error)
+	VersionContext(ctx context.Context) ([]byte, error)
 	SendCmd(int, []byte) error
+	SendCmdAsync(cmd int, data []byte) *Future
+	SendRecvAsync(data []byte) *Future
 	Reset() error
 	TextInsertPoint(int, int) error
-	GetTextPoint() ([]byte, error)
+	GetTextPoint() (x, y int, err error)
+	GetTextPointContext(ctx context.Context) (x, y int, err error)
 	TextPoint(int, int) func(data string) error
 	TextWindow(int, int, int, int) error
 	TextColour(int, int, int) error
@@ -58,6 +98,8 @@ type Display interface {
 	UpdateBargraphValue(int, int) ([]byte, error)
 	UpdateTraceValue(int, int) error
 	RunScript(string) error
+	RunScriptContext(ctx context.Context, filename string) error
+	RunResetContext(ctx context.Context) error
 	LoadBitmapLegcay(id int, filename string) error
 	DisplayBitmapLegcay(id int, x, y int) error
 	ClearBitmapLegacy(id int) error
@@ -66,33 +108,71 @@ type Display interface {
 
 	BitmapLoad(int, string) error
 	BitmapCapture(id int, left, top, width, height int) error
+	BitmapLoadContext(ctx context.Context, id int, filename string) error
+	BitmapCaptureContext(ctx context.Context, id int, left, top, width, height int) error
 	BuzzerActive(frec, time int) error
 	CreateObject(id int, objectType GTT25ObjectType) error
 	DestroyObject(id int) error
 	BaseObjectBeginUpdate(id int) error
 	BaseObjectEndUpdate(id int) error
+	SetFocus(id int) error
+	Update(id int, fn func(tx *Tx) error) error
 	ObjectListGet(id, itemIndex int) error
+	CreateObjectContext(ctx context.Context, id int, objectType GTT25ObjectType) error
+	DestroyObjectContext(ctx context.Context, id int) error
+	BaseObjectBeginUpdateContext(ctx context.Context, id int) error
+	BaseObjectEndUpdateContext(ctx context.Context, id int) error
+	SetFocusContext(ctx context.Context, id int) error
+	UpdateContext(ctx context.Context, id int, fn func(tx *Tx) error) error
+	ObjectListGetContext(ctx context.Context, id, itemIndex int) error
 	SetPropertyValueU16(id int, prpType GTT25PropertyType) func(value int) error
 	SetPropertyValueS16(id int, prpType GTT25PropertyType) func(value int) error
 	SetPropertyValueU8(id int, prpType GTT25PropertyType) func(value int) error
 	SetPropertyText(id int, prpType GTT25PropertyType) func(text string) error
-	GetPropertyValueU16(id int, prpType GTT25PropertyType) func() ([]byte, error)
-	GetPropertyValueS16(id int, prpType GTT25PropertyType) func() ([]byte, error)
+	SetPropertyValueS32(id int, prpType GTT25PropertyType) func(value int) error
+	SetPropertyBool(id int, prpType GTT25PropertyType) func(value bool) error
+	SetProperty(id int, prpType GTT25PropertyType, value interface{}) error
+	GetPropertyValueU16(id int, prpType GTT25PropertyType) func() (uint16, error)
+	GetPropertyValueS16(id int, prpType GTT25PropertyType) func() (int16, error)
 	GetPropertyValueU8(id int, prpType GTT25PropertyType) func() (byte, error)
-	GetPropertyText(id int, prpType GTT25PropertyType) func() ([]byte, error)
-
-	ChangeTouchReporting(style int) error
-	GetTouchReporting() ([]byte, error)
-
-	GetToggleState(id int) ([]byte, error)
-	GetSliderValue(id int) ([]byte, error)
+	GetPropertyText(id int, prpType GTT25PropertyType) func() (string, error)
+	GetPropertyValueS32(id int, prpType GTT25PropertyType) func() (int32, error)
+	GetPropertyBool(id int, prpType GTT25PropertyType) func() (bool, error)
+	SetPropertyValueU16Context(ctx context.Context, id int, prpType GTT25PropertyType) func(value int) error
+	SetPropertyValueS16Context(ctx context.Context, id int, prpType GTT25PropertyType) func(value int) error
+	SetPropertyValueU8Context(ctx context.Context, id int, prpType GTT25PropertyType) func(value int) error
+	SetPropertyTextContext(ctx context.Context, id int, prpType GTT25PropertyType) func(text string) error
+	SetPropertyValueS32Context(ctx context.Context, id int, prpType GTT25PropertyType) func(value int) error
+	SetPropertyBoolContext(ctx context.Context, id int, prpType GTT25PropertyType) func(value bool) error
+	SetPropertyContext(ctx context.Context, id int, prpType GTT25PropertyType, value interface{}) error
+	GetPropertyValueU16Context(ctx context.Context, id int, prpType GTT25PropertyType) func() (uint16, error)
+	GetPropertyValueS16Context(ctx context.Context, id int, prpType GTT25PropertyType) func() (int16, error)
+	GetPropertyValueU8Context(ctx context.Context, id int, prpType GTT25PropertyType) func() (byte, error)
+	GetPropertyTextContext(ctx context.Context, id int, prpType GTT25PropertyType) func() (string, error)
+	GetPropertyValueS32Context(ctx context.Context, id int, prpType GTT25PropertyType) func() (int32, error)
+	GetPropertyBoolContext(ctx context.Context, id int, prpType GTT25PropertyType) func() (bool, error)
+
+	ChangeTouchReporting(style TouchReportingStyle) error
+	GetTouchReporting() (TouchReportingStyle, error)
+	GetTouchReportingContext(ctx context.Context) (TouchReportingStyle, error)
+
+	GetToggleState(id int) (int, error)
+	GetSliderValue(id int) (int, error)
+	GetToggleStateContext(ctx context.Context, id int) (int, error)
+	GetSliderValueContext(ctx context.Context, id int) (int, error)
 
 	WriteScratch(addr int, data []byte) error
 	ReadScratch(addr, size int) ([]byte, error)
+	ReadScratchContext(ctx context.Context, addr, size int) ([]byte, error)
 	Listen() error
 	ListenWithContext(ctx context.Context) error
+	State() State
+	StateChanges() (<-chan State, func())
 	Events() (chan *Event, error)
+	Subscribe(filter EventFilter) (<-chan *Event, func())
+	DroppedEvents() uint64
 	StopListen()
+	Supervise(ctx context.Context, opt *SuperviseOptions) (<-chan ConnState, error)
 	AnimationStartStop(id, action int) error
 	AnimationSetFrame(id, state int) error
 	AnimationStopAll() error
@@ -101,19 +181,75 @@ type Display interface {
 type display struct {
 	options *PortOptions
 	status  uint32
-	port    *serial.Port
-	mux     sync.Mutex
-	wmux    sync.Mutex
+	smux    sync.Mutex
+	// stateChanges are the channels returned by StateChanges.
+	stateChanges map[chan State]struct{}
+	open         func() (io.ReadWriteCloser, error)
+	// lmux guards the lifecycle: port, listener, script and supervision.
+	// It is never held while waiting the device.
+	lmux     sync.Mutex
+	port     io.ReadWriteCloser
+	listener *listener
+	mux      sync.Mutex
+	wmux     sync.Mutex
 	// muxRecv    sync.Mutex
-	bufResp chan []byte
-	chEvent chan []byte
-	cancel  func()
+	decoder FrameDecoder
+	// read is the port read left running by a recv whose ctx was done.
+	read   *portRead
+	events eventHub
+	// script is the last script run, for the supervisor.
+	script      string
+	supervision *supervision
+}
+
+// portRead is a Read of the port run by recv in its own goroutine, so recv
+// can return when its ctx is done while Read blocks. The next recv waits for
+// the same read instead of starting another one: no byte is lost.
+type portRead struct {
+	port io.ReadWriteCloser
+	done chan struct{}
+	buf  []byte
+	n    int
+	err  error
+	// elapsed is the time Read blocked.
+	elapsed time.Duration
+}
+
+func startRead(port io.ReadWriteCloser) *portRead {
+	r := &portRead{
+		port: port,
+		done: make(chan struct{}),
+		buf:  make([]byte, bufferLen),
+	}
+	go func() {
+		defer close(r.done)
+		tn := time.Now()
+		r.n, r.err = port.Read(r.buf)
+		r.elapsed = time.Since(tn)
+	}()
+	return r
+}
+
+// listener is a running Listen. Every Listen creates a new one, so the
+// readers of a stopped listener never see the channels of the next one.
+type listener struct {
+	cancel func()
+	// done is closed when the listen goroutine returns, after err is set.
+	done chan struct{}
+	// err stopped the listener, nil when it was stopped by its context.
+	err error
+	// bufResp are the responses without a pending request (multi packet
+	// replies of RunScript and RunReset, Recv).
+	bufResp chan *Response
+	pending *pendingTable
 }
 
 const (
-	OPENED uint32 = iota
+	OPENED State = iota
 	CLOSED
 	LISTEN
+	// UNRESPONSIVE is a listening display that missed the heartbeats.
+	UNRESPONSIVE
 )
 
 const (
@@ -124,58 +260,115 @@ const (
 )
 
 const (
-	timeoutRead   time.Duration = 600 * time.Millisecond
-	bufferLen     int           = 1024
-	maxCountError int           = 5
+	timeoutRead     time.Duration = 600 * time.Millisecond
+	bufferLen       int           = 1024
+	maxCountError   int           = 5
+	bufferResponses int           = 16
 )
 
 // Create a new Display device
 func NewDisplay(opt *PortOptions) Display {
 	disp := &display{}
 	disp.options = opt
-	disp.status = CLOSED
+	disp.status = uint32(CLOSED)
+	disp.open = func() (io.ReadWriteCloser, error) {
+		config := &serial.Config{
+			Name:        opt.Port,
+			Baud:        opt.Baud,
+			ReadTimeout: opt.ReadTimeout,
+		}
+		return serial.OpenPort(config)
+	}
+	return disp
+}
+
+// Create a new Display device over an already opened transport (pipe,
+// socket, test double, ...). Port and Baud in opt are ignored, opt can be nil.
+// Open must still be called before use; Close closes rw.
+func NewDisplayWithTransport(rw io.ReadWriteCloser, opt *PortOptions) Display {
+	if opt == nil {
+		opt = &PortOptions{}
+	}
+	disp := &display{}
+	disp.options = opt
+	disp.status = uint32(CLOSED)
+	disp.open = func() (io.ReadWriteCloser, error) {
+		if rw == nil {
+			return nil, ErrorDevNull
+		}
+		return rw, nil
+	}
+	return disp
+}
+
+// Create a new Display device over the transports returned by dial, called
+// by Open and by the supervisor to reconnect. Port and Baud in opt are
+// ignored, opt can be nil.
+func NewDisplayWithDialer(dial func() (io.ReadWriteCloser, error), opt *PortOptions) Display {
+	if opt == nil {
+		opt = &PortOptions{}
+	}
+	disp := &display{}
+	disp.options = opt
+	disp.status = uint32(CLOSED)
+	disp.open = dial
 	return disp
 }
 
 // Open device comunication channel
 func (m *display) Open() error {
-	if m.status == OPENED || m.status == LISTEN {
-		return nil
-	}
+	return m.openContext(context.Background())
+}
 
-	config := &serial.Config{
-		Name:        m.options.Port,
-		Baud:        m.options.Baud,
-		ReadTimeout: m.options.ReadTimeout,
+// openContext opens the port unless ctx is done: a supervisor stopped by
+// Close never reopens the port.
+func (m *display) openContext(ctx context.Context) error {
+	m.lmux.Lock()
+	defer m.lmux.Unlock()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if m.state() != CLOSED {
+		return nil
 	}
 
-	var err error
-	m.port, err = serial.OpenPort(config)
+	port, err := m.open()
 	if err != nil {
 		return err
 	}
+	m.port = port
 
-	m.status = OPENED
+	m.setState(OPENED)
 	return nil
 }
 
-// Clsoe device comunication channel
+// Clsoe device comunication channel. It stops the listener and the
+// supervisor, and waits for the listener to return. Safe for concurrent use.
 func (m *display) Close() error {
-	fmt.Println("CLOSE gtt43a")
-	defer func() {
-		m.status = CLOSED
-	}()
-	if m.cancel != nil {
-		m.cancel()
+	m.logger().Debug("close")
+	m.lmux.Lock()
+	if m.supervision != nil {
+		m.supervision.cancel()
 	}
-	if m.port == nil {
-		return nil
+	l := m.listener
+	port := m.port
+	m.listener = nil
+	m.port = nil
+	m.setState(CLOSED)
+	m.closeStateChanges()
+	m.lmux.Unlock()
+
+	if l != nil {
+		l.cancel()
 	}
-	if err := m.port.Close(); err != nil {
-		return err
+	var err error
+	if port != nil {
+		err = port.Close()
 	}
-
-	return nil
+	if l != nil {
+		<-l.done
+	}
+	return err
 }
 
 // Listen is a go rutine that listening serial port to detect messages
@@ -187,192 +380,155 @@ func (m *display) Listen() error {
 // ListenWithContext is a go rutine that listening serial port to detect messages
 // Return channel with  messages (Event struct)
 func (m *display) ListenWithContext(contxt context.Context) error {
-	if m.status == LISTEN {
+	if contxt == nil {
+		contxt = context.TODO()
+	}
+	m.lmux.Lock()
+	defer m.lmux.Unlock()
+	if m.listening() {
 		return fmt.Errorf("error: already Listening display")
 	}
-	if m.status != OPENED {
+	if m.state() != OPENED {
 		return fmt.Errorf("error: port serial is closed")
 	}
-	if m.cancel != nil {
-		m.cancel()
-	}
 
-	var ctx context.Context
-	var cancel func()
-	if contxt != nil {
-		ctx, cancel = context.WithCancel(contxt)
-		m.cancel = cancel
-	} else {
-		ctx, cancel = context.WithCancel(context.TODO())
-		m.cancel = cancel
+	ctx, cancel := context.WithCancel(contxt)
+	l := &listener{
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		bufResp: make(chan *Response, bufferResponses),
+		pending: newPendingTable(),
 	}
+	m.listener = l
 
 	countError := 0
-	m.bufResp = make(chan []byte)
-	m.chEvent = make(chan []byte)
-	fmt.Println("START listen")
-	ch := make(chan []byte)
+	m.logger().Debug("start listen")
 	go func() {
 		defer func() {
-			fmt.Println("STOP listen")
-			close(m.chEvent)
-			close(ch)
-			if cancel != nil {
-				cancel()
-			}
-			if m.status == LISTEN {
-				m.status = OPENED
+			m.logger().Debug("stop listen")
+			cancel()
+			m.lmux.Lock()
+			if m.listener == l {
+				m.listener = nil
+				if !m.swapState(LISTEN, OPENED) {
+					m.swapState(UNRESPONSIVE, OPENED)
+				}
 			}
+			m.lmux.Unlock()
+			close(l.done)
 		}()
 
-		funcRead := func(v []byte) {
-			lenValue := 0
-			if len(v) <= 0 {
-				return
-			} else if len(v) > 2 && bytes.Equal(v[:2], []byte{0xFC, 0xEB}) {
-				log.Printf("respuesta low 0 [% X]\n", v[:])
-				if len(v) > 8 {
-					lenValue = int(binary.BigEndian.Uint16(v[2:4]))
-					msg := make([]byte, 0)
-					msg = append(msg, v[1])
-					msg = append(msg, v[4:lenValue+4]...)
-					select {
-					case m.chEvent <- msg:
-					case <-time.After(timeoutRead):
-						// default:
-						log.Printf("evento ????X [% X]\n", msg)
-					}
-				}
-			} else if len(v) > 2 && bytes.Equal(v[:2], []byte{0xFC, 0x87}) {
-				log.Printf("respuesta low 1 [% X]\n", v[:])
-				if len(v) > 5 {
-					lenValue = int(binary.BigEndian.Uint16(v[2:4]))
-					msg := make([]byte, 0)
-					msg = append(msg, v[1])
-					msg = append(msg, v[4:lenValue+4]...)
-					select {
-					case m.chEvent <- msg:
-					case <-time.After(timeoutRead):
-						// default:
-						log.Printf("evento ????X [% X]\n", msg)
-					}
+		funcRead := func(frame Frame) {
+			switch {
+			case frame.Cmd == 0xEB, frame.Cmd == 0x86, frame.Cmd == 0x87:
+				m.logger().Debug("event", "dir", "rx", "cmd", hexBytes{frame.Cmd}, "data", hexBytes(frame.Payload))
+				event, ok := EventFromFrame(frame)
+				if !ok {
+					m.logger().Warn("bad event dropped", "cmd", hexBytes{frame.Cmd}, "data", hexBytes(frame.Payload))
+					return
 				}
-			} else if len(v) > 2 && bytes.Equal(v[:2], []byte{0xFC, 0xFB}) {
-				log.Printf("respuesta low 2 [% X]\n", v[:])
-				select {
-				case m.bufResp <- v[:lenValue+4]:
-				default:
-					log.Printf("msg ????X [% X]\n", v[:lenValue+4])
+				if m.events.publish(event) > 0 {
+					m.logger().Warn("event dropped", "cmd", hexBytes{frame.Cmd}, "data", hexBytes(frame.Payload))
 				}
-			} else if len(v) > 4 && v[0] == byte(0xFC) {
-				log.Printf("respuesta low 3 [% X]\n", v[:])
-				lenValue = int(binary.BigEndian.Uint16(v[2:4]))
-				if len(v) < lenValue+4 {
+			default:
+				m.logger().Debug("response", "dir", "rx", "cmd", hexBytes{frame.Cmd}, "data", hexBytes(frame.Payload))
+				res, err := NewResponse(frame)
+				if err != nil {
+					m.logger().Warn("bad response", "err", err)
 					return
 				}
-				log.Printf("respuesta low 3 [% X]\n", v[:])
-				select {
-				case m.bufResp <- v[:lenValue+4]:
-				default:
-					log.Printf("msg ????X [% X]\n", v[:lenValue+4])
+				if l.pending.dispatch(res) {
+					return
 				}
-			} else {
-				log.Printf("respuesta low 4 [% X]\n", v[:])
 				select {
-				case m.bufResp <- v[:]:
+				case l.bufResp <- res:
 				default:
-					log.Printf("msg ????X [% X]\n", v[:])
+					m.logger().Warn("response dropped", "cmd", hexBytes{frame.Cmd}, "data", hexBytes(frame.Payload))
 				}
 			}
 		}
+		decoder := &FrameDecoder{}
 		for {
-			/**/
 			select {
 			case <-ctx.Done():
 				return
 			default:
 			}
-			buf, err := m.recv()
+			buf, err := m.recv(ctx)
 			if err != nil {
-				if countError >= maxCountError {
+				if ctx.Err() != nil {
+					return
+				}
+				if countError >= m.maxListenErrors() {
+					m.logger().Error("stop listen, too many read errors", "err", err)
+					l.err = err
 					return
 				}
 				countError++
 				continue
 			}
 			countError = 0
-			if len(buf) <= 0 {
-				continue
+			frames := decoder.Decode(buf)
+			if m.resync(decoder, len(buf) == 0) {
+				frames = append(frames, decoder.Decode(nil)...)
 			}
-			for {
-				if len(buf) > 0 && buf[0] == byte(0xFC) {
-					if len(buf) > 4 {
-						lenValue := int(binary.BigEndian.Uint16(buf[2:4]))
-						if len(buf) >= lenValue+4 {
-							funcRead(buf[0 : lenValue+4])
-							buf = buf[4+lenValue:]
-							continue
-						}
-					}
-					funcRead(buf[:])
-					break
-				} else {
-					funcRead(buf[:])
-				}
-				break
+			for _, frame := range frames {
+				funcRead(frame)
 			}
 		}
 	}()
-	m.status = LISTEN
+	m.setState(LISTEN)
+	if m.options.HeartbeatInterval > 0 {
+		go m.heartbeat(ctx, m.options.HeartbeatInterval)
+	}
 	return nil
 }
 
+// StopListen stops the listener and the supervisor, and waits for the
+// listener to return. Safe for concurrent use.
 func (m *display) StopListen() {
-	if m.cancel != nil {
-		m.cancel()
+	m.lmux.Lock()
+	if m.supervision != nil {
+		m.supervision.cancel()
+	}
+	l := m.listener
+	m.lmux.Unlock()
+	if l != nil {
+		l.cancel()
+		<-l.done
 	}
 }
 
-// Primitive function to send and recieve bytes to and from display device.
-// recv, flag to wait a response form device.
-func (m *display) SendRecv(data []byte) ([]byte, error) {
-	m.wmux.Lock()
-	defer m.wmux.Unlock()
-	fmt.Println("SendRecv ########")
-	defer fmt.Println("end SendRecv ########")
+// currentListener returns the running listener, nil when not listening.
+func (m *display) currentListener() *listener {
+	m.lmux.Lock()
+	defer m.lmux.Unlock()
+	return m.listener
+}
 
-	if err := m.send(data); err != nil {
-		return nil, err
-	}
+// currentPort returns the open port, nil when closed.
+func (m *display) currentPort() io.ReadWriteCloser {
+	m.lmux.Lock()
+	defer m.lmux.Unlock()
+	return m.port
+}
 
-	if m.status == LISTEN {
-		tAfter1 := time.After(timeoutRead)
-		count := 0
-		for {
-			select {
-			case res := <-m.bufResp:
-				fmt.Printf("count: %d, %X\n", (count), res)
-				count++
-				if len(res) > 0 {
-					return res[:], nil
-				}
-			case <-tAfter1:
-				log.Println("timeoutRead ////")
-				return nil, ErrorDevTimeout
-			}
-		}
-	}
-	/**/
-	res, err := m.recv()
-	if err != nil {
-		return nil, err
-	}
-	return res, nil
+// Primitive function to send and recieve bytes to and from display device.
+// The response is the reply to the command in data: same command code, and
+// same sub command for GTT2.5 object commands. Safe for concurrent use.
+func (m *display) SendRecv(data []byte) (*Response, error) {
+	return m.SendRecvContext(context.Background(), data)
+}
+
+// SendRecvContext is SendRecv bounded by ctx. Without a deadline in ctx the
+// response timeout applies.
+func (m *display) SendRecvContext(ctx context.Context, data []byte) (*Response, error) {
+	return m.sendRecvKey(ctx, data)
 }
 
 // Send bytes data to device. Don't wait response.
 func (m *display) Send(data []byte) error {
-	if m.status == CLOSED {
+	if m.state() == CLOSED {
 		return fmt.Errorf("device CLOSED")
 	}
 	return m.send(data)
@@ -383,89 +539,201 @@ func (m *display) send(data []byte) error {
 	if data == nil {
 		return ErrorDevNull
 	}
-	if m.status == CLOSED {
+	port := m.currentPort()
+	if m.state() == CLOSED || port == nil {
 		return ErrorDevClosed
 	}
-	n, err := m.port.Write(data)
+	n, err := port.Write(data)
 	if err != nil || n <= 0 {
 		return fmt.Errorf("error Write: %w", err)
 	}
 	if n <= 0 {
 		return ErrorDevEmptyWrite
 	}
-	/**/
-	log.Printf("request: [% X]\n", data)
-	/**/
+	if len(data) > 1 && data[0] == 0xFE {
+		m.logger().Debug("request", "dir", "tx", "cmd", hexBytes{data[1]}, "data", hexBytes(data[2:]))
+	} else {
+		m.logger().Debug("request", "dir", "tx", "data", hexBytes(data))
+	}
 	return nil
 }
 
 /**/
-func (m *display) Recv() ([]byte, error) {
+func (m *display) Recv() (*Response, error) {
+	return m.RecvContext(context.Background())
+}
 
-	if m.status == LISTEN {
-		count := 0
-		for {
+// RecvContext is Recv bounded by ctx. Without a deadline in ctx the response
+// timeout applies.
+func (m *display) RecvContext(ctx context.Context) (*Response, error) {
+	ctx, cancel := m.requestContext(ctx)
+	defer cancel()
+	return m.recvContext(ctx, nil)
+}
+
+// requestContext bounds ctx with the response timeout when it has no deadline.
+func (m *display) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.responseTimeout())
+}
+
+func (m *display) responseTimeout() time.Duration {
+	if m.options.ResponseTimeout > 0 {
+		return m.options.ResponseTimeout
+	}
+	return timeoutRead
+}
+
+func (m *display) maxListenErrors() int {
+	if m.options.MaxListenErrors > 0 {
+		return m.options.MaxListenErrors
+	}
+	return maxCountError
+}
+
+// retry runs an idempotent request following the retry policy. With a
+// policy, every attempt gets its own ctx bounded by the response timeout, so
+// a caller deadline longer than it leaves time for the retries.
+func (m *display) retry(ctx context.Context, request func(ctx context.Context) error) error {
+	policy := m.options.Retry
+	if policy == nil {
+		return request(ctx)
+	}
+	attempt := func() error {
+		ctx, cancel := context.WithTimeout(ctx, m.responseTimeout())
+		defer cancel()
+		return request(ctx)
+	}
+	err := attempt()
+	backoff := policy.Backoff
+	for i := 0; i < policy.Count && errors.Is(err, ErrorDevTimeout); i++ {
+		m.logger().Warn("retry", "attempt", i+1, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+		err = attempt()
+	}
+	return err
+}
+
+// contextError maps an expired deadline to ErrorDevTimeout.
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrorDevTimeout
+	}
+	return ctx.Err()
+}
+
+// recvContext waits for the next response accepted by match (any response if
+// match is nil) until ctx is done.
+func (m *display) recvContext(ctx context.Context, match func(*Response) bool) (*Response, error) {
+	for {
+		var res *Response
+		if l := m.currentListener(); l != nil {
 			select {
-			case res := <-m.bufResp:
-				fmt.Printf("count: %d, %X\n", (count), res)
-				count++
-				if len(res) > 0 {
-					return res[:], nil
-				}
-			case <-time.After(timeoutRead):
-				return nil, ErrorDevTimeout
+			case res = <-l.bufResp:
+			case <-l.done:
+				return nil, ErrorDevClosed
+			case <-ctx.Done():
+				m.logger().Warn("response timeout", "err", ctx.Err())
+				return nil, contextError(ctx)
 			}
+		} else {
+			var err error
+			res, err = m.readResponse(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if match == nil || match(res) {
+			return res, nil
 		}
 	}
+}
 
-	res, err := m.recv()
-	if err != nil {
-		return nil, err
+// readResponse reads from the port, when it is not listening, until a
+// complete frame is received or ctx is done.
+func (m *display) readResponse(ctx context.Context) (*Response, error) {
+	for {
+		m.mux.Lock()
+		frame, ok := m.decoder.Next()
+		m.mux.Unlock()
+		if ok {
+			return NewResponse(frame)
+		}
+		buf, err := m.recv(ctx)
+		if err != nil {
+			return nil, err
+		}
+		m.mux.Lock()
+		m.decoder.Write(buf)
+		m.resync(&m.decoder, len(buf) == 0)
+		m.mux.Unlock()
+	}
+}
+
+// resync gives up the frame being assembled by decoder when the rest of it
+// is not coming: the read was idle, or the frame is older than the response
+// timeout. It returns true if a frame was given up.
+func (m *display) resync(decoder *FrameDecoder, idle bool) bool {
+	if decoder.Buffered() == 0 {
+		return false
+	}
+	if !idle && decoder.Age() < m.responseTimeout() {
+		return false
 	}
-	return res, nil
+	m.logger().Warn("partial frame dropped", "buffered", decoder.Buffered())
+	decoder.Resync()
+	return true
 }
 
 // Primitive function to send and recieve bytes to and from display device.
-// recv, flag to wait a response form device.
-func (m *display) recv() ([]byte, error) {
+// recv, flag to wait a response form device. It returns when ctx is done,
+// even if the port blocks in Read.
+func (m *display) recv(ctx context.Context) ([]byte, error) {
 
 	m.mux.Lock()
 	defer m.mux.Unlock()
 
-	if m.status == CLOSED {
+	if m.state() == CLOSED {
 		return nil, ErrorDevClosed
 	}
 
-	if m.port == nil {
+	port := m.currentPort()
+	if port == nil {
 		return nil, ErrorDevNull
 	}
 
-	reader := bufio.NewReader(m.port)
-
-	// tn := time.Now()
-	// buf, err := reader.ReadBytes('\xFE')
-	// if err != nil {
-	// 	if !errors.Is(err, io.EOF) {
-	// 		return nil, err
-	// 	}
-	// 	if m.options.ReadTimeout > 0 && time.Since(tn) < m.options.ReadTimeout/10 {
-	// 		return nil, err
-	// 	}
-	// 	// fmt.Println("recv timeout")
-	// }
-	// n := len(buf)
-	// if n <= 0 {
-	// 	return nil, nil
-	// }
-
-	tn := time.Now()
-	buf := make([]byte, 1024)
-	n, err := reader.Read(buf)
+	// Read straight from the port: a reader created per call would lose
+	// the bytes it buffered beyond buf when recv returns.
+	r := m.read
+	if r == nil || r.port != port {
+		r = startRead(port)
+		m.read = r
+	}
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return nil, contextError(ctx)
+	}
+	m.read = nil
+	n, err := r.n, r.err
 	if err != nil {
-		if !errors.Is(err, io.EOF) {
+		// A serial port with ReadTimeout returns io.EOF when nothing
+		// arrives in time. Without ReadTimeout, io.EOF is the end of the
+		// transport.
+		if !errors.Is(err, io.EOF) || m.options.ReadTimeout <= 0 {
 			return nil, err
 		}
-		if m.options.ReadTimeout > 0 && time.Since(tn) < m.options.ReadTimeout/10 {
+		if r.elapsed < m.options.ReadTimeout/10 {
 			return nil, err
 		}
 		// fmt.Println("recv timeout")
@@ -475,72 +743,26 @@ func (m *display) recv() ([]byte, error) {
 		return nil, nil
 	}
 	response := make([]byte, 0)
-	response = append(response, buf[:n]...)
-	log.Printf("Response_0: [% X]\n", buf[:n])
+	response = append(response, r.buf[:n]...)
+	m.logger().Debug("read", "dir", "rx", "data", hexBytes(r.buf[:n]))
 	return response, nil
 }
 
 // Send a command to display device
 // cmd, id for the command
 // wait response
-func (m *display) SendRecvCmd(cmd int, data []byte) ([]byte, error) {
-	m.wmux.Lock()
-	defer m.wmux.Unlock()
-	fmt.Println("SendRecvCmd ########")
-	defer fmt.Println("end SendRecvCmd ########")
+func (m *display) SendRecvCmd(cmd int, data []byte) (*Response, error) {
+	return m.SendRecvCmdContext(context.Background(), cmd, data)
+}
+
+// SendRecvCmdContext is SendRecvCmd bounded by ctx. Without a deadline in ctx
+// the response timeout applies.
+func (m *display) SendRecvCmdContext(ctx context.Context, cmd int, data []byte) (*Response, error) {
 	dat1 := []byte{0xFE, byte(cmd)}
 	if data != nil {
 		dat1 = append(dat1, data...)
 	}
-	var res []byte
-
-	//n, res := m.SendRecv(dat1)
-	if err := m.send(dat1); err != nil {
-		return nil, err
-	}
-
-	if m.status == LISTEN {
-		after := time.After(timeoutRead)
-		tick := time.NewTicker(10 * time.Millisecond)
-		defer tick.Stop()
-		// count := 0
-	for_src:
-		for {
-			select {
-			case res = <-m.bufResp:
-				// fmt.Printf("count: %d, %X\n", (count), res)
-				// count++
-				if len(res) > 1 && res[1] == byte(cmd) {
-					fmt.Printf("SendRecvCmd response: %X\n", (res))
-					break for_src
-				}
-				continue
-			case <-after:
-				log.Println("timeoutRead")
-				return res, ErrorDevTimeout
-			}
-		}
-	} else {
-		/**/
-		res, err := m.recv()
-		if err != nil {
-			return nil, err
-		}
-		return res, nil
-		/**/
-	}
-
-	switch {
-	case len(res) <= 0:
-		return nil, ErrorDevEmptyRead
-	case len(res) < 4:
-		return nil, errors.New("incomplete response")
-	case res[0] != byte(0xFC):
-		return nil, errors.New("wrong response")
-	case res[1] != byte(cmd):
-		return nil, fmt.Errorf("incorrect response, CMD: [%X]", res[1])
-	}
-	return res[4:], nil
+	return m.sendRecvKey(ctx, dat1)
 }
 
 // Send a Command to display device.
@@ -556,7 +778,20 @@ func (m *display) SendCmd(cmd int, data []byte) error {
 
 // Send echo data and to wait for a response.
 func (m *display) Echo(data []byte) ([]byte, error) {
-	return m.SendRecvCmd(0xFF, data)
+	return m.EchoContext(context.Background(), data)
+}
+
+// EchoContext is Echo bounded by ctx.
+func (m *display) EchoContext(ctx context.Context, data []byte) ([]byte, error) {
+	var res *Response
+	err := m.retry(ctx, func(ctx context.Context) (err error) {
+		res, err = m.SendRecvCmdContext(ctx, 0xFF, data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Payload, nil
 }
 
 // Send reset command to display device
@@ -566,7 +801,20 @@ func (m *display) Reset() error {
 
 // Request Version and wait for a response.
 func (m *display) Version() ([]byte, error) {
-	return m.SendRecvCmd(0x00, nil)
+	return m.VersionContext(context.Background())
+}
+
+// VersionContext is Version bounded by ctx.
+func (m *display) VersionContext(ctx context.Context) ([]byte, error) {
+	var res *Response
+	err := m.retry(ctx, func(ctx context.Context) (err error) {
+		res, err = m.SendRecvCmdContext(ctx, 0x00, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.Payload, nil
 }
 
 // Clear actual Screen
@@ -576,71 +824,104 @@ func (m *display) ClrScreen() error {
 
 // RunReset command. Software reset
 func (m *display) RunReset() error {
+	return m.RunResetContext(context.Background())
+}
+
+// RunResetContext is RunReset bounded by ctx. Without a deadline in ctx the
+// response timeout applies to every packet waited from the device.
+func (m *display) RunResetContext(ctx context.Context) error {
 	m.wmux.Lock()
 	defer m.wmux.Unlock()
-	fmt.Println("runreset ########")
-	defer fmt.Println("end runReset ########")
+	m.logger().Debug("runReset")
 	if err := m.SendCmd(0x01, nil); err != nil {
 		return err
 	}
-	var res []byte
+	var res *Response
 	count := 0
 	for range make([]int, 8) {
-		res, _ = m.Recv()
+		res = m.recvWaitPacket(ctx)
 		// fmt.Printf("////////// 1: %X\n", res)
-		if len(res) > 1 {
-			if res[1] == 0xFB {
+		if res != nil {
+			if res.Cmd == 0xFB {
 				if count > 2 {
 					return nil
 				}
 				count++
 			}
 		}
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
 	}
 	// fmt.Printf("////////// 2: %X\n", res)
-	if len(res) > 1 {
-		if res[1] == 0xFA || res[1] == 0xFB {
-			log.Println("without err")
+	if res != nil {
+		if res.Cmd == 0xFA || res.Cmd == 0xFB {
+			m.logger().Debug("end without confirmation", "cmd", hexBytes{res.Cmd})
 			return nil
 		}
 	}
-	return fmt.Errorf("bad response: [% X]", res)
+	if res == nil {
+		return ErrorDevTimeout
+	}
+	return fmt.Errorf("bad response: [% X]", res.Bytes())
 }
 
 // Run script binary. The filename path is a local path in display device
 func (m *display) RunScript(filename string) error {
+	return m.RunScriptContext(context.Background(), filename)
+}
+
+// RunScriptContext is RunScript bounded by ctx. Without a deadline in ctx the
+// response timeout applies to every packet waited from the device.
+func (m *display) RunScriptContext(ctx context.Context, filename string) error {
 	m.wmux.Lock()
 	defer m.wmux.Unlock()
-	fmt.Println("runScript ########")
-	defer fmt.Println("end runScript ########")
+	m.logger().Debug("runScript", "file", filename)
 	data := []byte(filename)
 	data = append(data, 0x00)
 	if err := m.SendCmd(0x5D, data); err != nil {
 		return err
 	}
-	var res []byte
+	m.lmux.Lock()
+	m.script = filename
+	m.lmux.Unlock()
+	var res *Response
 	count := 0
 	for range make([]int, 8) {
-		res, _ = m.Recv()
+		res = m.recvWaitPacket(ctx)
 		// fmt.Printf("////////// 1: %X\n", res)
-		if len(res) > 1 {
-			if res[1] == 0xFB {
+		if res != nil {
+			if res.Cmd == 0xFB {
 				if count > 0 {
 					return nil
 				}
 				count++
 			}
 		}
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
 	}
 	// fmt.Printf("////////// 2: %X\n", res)
-	if len(res) > 1 {
-		if res[1] == 0xFA || res[1] == 0xFB {
-			log.Println("without err")
+	if res != nil {
+		if res.Cmd == 0xFA || res.Cmd == 0xFB {
+			m.logger().Debug("end without confirmation", "cmd", hexBytes{res.Cmd})
 			return nil
 		}
 	}
 
-	return fmt.Errorf("bad response: [% X]", res)
+	if res == nil {
+		return ErrorDevTimeout
+	}
+	return fmt.Errorf("bad response: [% X]", res.Bytes())
+}
+
+// recvWaitPacket waits one packet of a multi packet reply, nil on timeout.
+func (m *display) recvWaitPacket(ctx context.Context) *Response {
+	ctx, cancel := m.requestContext(ctx)
+	defer cancel()
+	res, _ := m.recvContext(ctx, nil)
+	return res
 }
 
 // Active buzzer in device.
@@ -659,21 +940,63 @@ func (m *display) BuzzerActive(frec, time int) error {
 }
 
 // Change Touch Reporting Style
-func (m *display) ChangeTouchReporting(style int) error {
+func (m *display) ChangeTouchReporting(style TouchReportingStyle) error {
 	return m.SendCmd(0x87, []byte{byte(style)})
 }
 
 // Get Touch Reporting Style
-func (m *display) GetTouchReporting() ([]byte, error) {
-	return m.SendRecvCmd(0x88, nil)
+func (m *display) GetTouchReporting() (TouchReportingStyle, error) {
+	return m.GetTouchReportingContext(context.Background())
 }
 
-func (m *display) GetToggleState(id int) ([]byte, error) {
-	return m.SendRecvCmd(171, []byte{byte(id & 0xFF)})
+// GetTouchReportingContext is GetTouchReporting bounded by ctx.
+func (m *display) GetTouchReportingContext(ctx context.Context) (TouchReportingStyle, error) {
+	var res *Response
+	err := m.retry(ctx, func(ctx context.Context) (err error) {
+		res, err = m.SendRecvCmdContext(ctx, 0x88, nil)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	style, err := res.Uint8()
+	return TouchReportingStyle(style), err
 }
 
-func (m *display) GetSliderValue(id int) ([]byte, error) {
-	return m.SendRecvCmd(167, []byte{byte(id & 0xFF)})
+func (m *display) GetToggleState(id int) (int, error) {
+	return m.GetToggleStateContext(context.Background(), id)
+}
+
+// GetToggleStateContext is GetToggleState bounded by ctx.
+func (m *display) GetToggleStateContext(ctx context.Context, id int) (int, error) {
+	var res *Response
+	err := m.retry(ctx, func(ctx context.Context) (err error) {
+		res, err = m.SendRecvCmdContext(ctx, 171, []byte{byte(id & 0xFF)})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	state, err := res.Uint8()
+	return int(state), err
+}
+
+func (m *display) GetSliderValue(id int) (int, error) {
+	return m.GetSliderValueContext(context.Background(), id)
+}
+
+// GetSliderValueContext is GetSliderValue bounded by ctx.
+func (m *display) GetSliderValueContext(ctx context.Context, id int) (int, error) {
+	var res *Response
+	err := m.retry(ctx, func(ctx context.Context) (err error) {
+		res, err = m.SendRecvCmdContext(ctx, 167, []byte{byte(id & 0xFF)})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	value, err := res.Int16()
+	return int(value), err
 }
 
 func (m *display) WriteScratch(addr int, data []byte) error {
@@ -689,6 +1012,11 @@ func (m *display) WriteScratch(addr int, data []byte) error {
 }
 
 func (m *display) ReadScratch(addr, size int) ([]byte, error) {
+	return m.ReadScratchContext(context.Background(), addr, size)
+}
+
+// ReadScratchContext is ReadScratch bounded by ctx.
+func (m *display) ReadScratchContext(ctx context.Context, addr, size int) ([]byte, error) {
 	dat1 := make([]byte, 0)
 	addrb := make([]byte, 2)
 	sizeb := make([]byte, 2)
@@ -696,15 +1024,19 @@ func (m *display) ReadScratch(addr, size int) ([]byte, error) {
 	binary.BigEndian.PutUint16(sizeb, uint16(size))
 	dat1 = append(dat1, addrb...)
 	dat1 = append(dat1, sizeb...)
-	datOut, err := m.SendRecvCmd(0xCD, dat1)
+	var res *Response
+	err := m.retry(ctx, func(ctx context.Context) (err error) {
+		res, err = m.SendRecvCmdContext(ctx, 0xCD, dat1)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-	if len(datOut) < 3 {
-		return nil, fmt.Errorf("bad response: [% X]", datOut)
+	if len(res.Payload) < 2 {
+		return nil, fmt.Errorf("bad response: [% X]", res.Bytes())
 	}
 
-	return datOut[2:], nil
+	return res.Payload[2:], nil
 }
 
 func (m *display) AnimationStartStop(id, action int) error {
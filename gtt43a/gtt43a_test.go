@@ -1,11 +1,16 @@
 package gtt43a
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	_ "strings"
 	"testing"
 	"time"
+
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
 )
 
 /**/
@@ -33,24 +38,24 @@ func TestAppDemo(t *testing.T) {
 	if err != nil {
 		log.Println(err)
 	} else {
-		if len(resp) > 0 {
-			log.Printf("response: [% X]\n", resp)
+		if resp != nil {
+			log.Printf("response: [% X]\n", resp.Bytes())
 		}
 	}
 	resp, err = m.SendRecv([]byte{0xFE, 0x87, 0x03})
 	if err != nil {
 		log.Println(err)
 	} else {
-		if len(resp) > 0 {
-			log.Printf("response: [% X]\n", resp)
+		if resp != nil {
+			log.Printf("response: [% X]\n", resp.Bytes())
 		}
 	}
 	resp, err = m.SendRecv([]byte{0xFE, 0x88})
 	if err != nil {
 		log.Println(err)
 	} else {
-		if len(resp) > 0 {
-			log.Printf("response: [% X]\n", resp)
+		if resp != nil {
+			log.Printf("response: [% X]\n", resp.Bytes())
 		}
 	}
 
@@ -67,9 +72,9 @@ func TestAppDemo(t *testing.T) {
 	go func() {
 		defer close(chRead)
 		for {
-			buf, err := m.Recv()
-			if err == nil && len(buf) > 0 {
-				chRead <- buf
+			res, err := m.Recv()
+			if err == nil {
+				chRead <- res.Bytes()
 			}
 		}
 	}()
@@ -94,3 +99,284 @@ break_for:
 	/**/
 	t.Log("Stop Logs")
 }
+
+func TestTransportEcho(t *testing.T) {
+	host, dev := net.Pipe()
+	defer dev.Close()
+
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := dev.Read(buf)
+			if err != nil {
+				return
+			}
+			if n < 2 || buf[0] != 0xFE || buf[1] != 0xFF {
+				continue
+			}
+			res := []byte{0xFC, 0xFF, 0x00, byte(n - 2)}
+			res = append(res, buf[2:n]...)
+			if _, err := dev.Write(res); err != nil {
+				return
+			}
+		}
+	}()
+
+	m := NewDisplayWithTransport(host, nil)
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer m.Close()
+
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	res, err := m.Echo([]byte{0x01, 0x02, 0x03})
+	if err != nil {
+		t.Fatalf("echo: %s", err)
+	}
+	if !bytes.Equal(res, []byte{0x01, 0x02, 0x03}) {
+		t.Errorf("echo response: [% X]", res)
+	}
+}
+
+// emulatorReadTimeout is the ReadTimeout of the emulator port: its reads
+// return io.EOF when nothing arrives in time, like a serial port.
+const emulatorReadTimeout = 100 * time.Millisecond
+
+func newEmulatedDisplay(t *testing.T, opt *emulator.Options) (Display, *emulator.Emulator) {
+	t.Helper()
+	e := emulator.New(opt)
+	m := NewDisplayWithTransport(e.Port(), &PortOptions{ReadTimeout: emulatorReadTimeout})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	t.Cleanup(func() {
+		m.Close()
+		e.Close()
+	})
+	return m, e
+}
+
+func TestEmulatorSendRecvCmd(t *testing.T) {
+	m, _ := newEmulatedDisplay(t, &emulator.Options{Version: []byte{0x02, 0x05}})
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+
+	res, err := m.Version()
+	if err != nil {
+		t.Fatalf("version: %s", err)
+	}
+	if !bytes.Equal(res, []byte{0x02, 0x05}) {
+		t.Errorf("version: [% X]", res)
+	}
+
+	if err := m.WriteScratch(0x10, []byte("scratch")); err != nil {
+		t.Fatalf("write scratch: %s", err)
+	}
+	res, err = m.ReadScratch(0x10, 7)
+	if err != nil {
+		t.Fatalf("read scratch: %s", err)
+	}
+	if string(res) != "scratch" {
+		t.Errorf("read scratch: %q", res)
+	}
+}
+
+func TestEmulatorVersionNotListening(t *testing.T) {
+	m, _ := newEmulatedDisplay(t, &emulator.Options{Version: []byte{0x02, 0x05}})
+
+	res, err := m.Version()
+	if err != nil {
+		t.Fatalf("version: %s", err)
+	}
+	if !bytes.Equal(res, []byte{0x02, 0x05}) {
+		t.Errorf("version: [% X]", res)
+	}
+}
+
+func TestEmulatorRunScriptAndReset(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+
+	if err := m.RunScript("GTTProject4\\Screen2\\Screen2.bin"); err != nil {
+		t.Fatalf("run script: %s", err)
+	}
+	if e.Script() != "GTTProject4\\Screen2\\Screen2.bin" {
+		t.Errorf("script: %q", e.Script())
+	}
+	if err := m.Reset(); err != nil {
+		t.Fatalf("reset: %s", err)
+	}
+}
+
+func TestEmulatorProperties(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+
+	if err := m.SetPropertyValueU16(9, SliderValue)(300); err != nil {
+		t.Fatalf("set U16: %s", err)
+	}
+	if v := e.Property(9, SliderValue); !bytes.Equal(v, []byte{0x01, 0x2C}) {
+		t.Errorf("stored value: [% X]", v)
+	}
+	res, err := m.GetPropertyValueU16(9, SliderValue)()
+	if err != nil {
+		t.Fatalf("get U16: %s", err)
+	}
+	if res != 300 {
+		t.Errorf("get U16: %d", res)
+	}
+
+	e.FailNext(emulator.StatusOutOfRange)
+	if err := m.SetPropertyValueU8(9, LabelFontSize)(12); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("expected ErrOutOfRange, got %v", err)
+	}
+}
+
+func TestEmulatorStatusError(t *testing.T) {
+	m, e := newEmulatedDisplay(t, &emulator.Options{Strict: true})
+	e.AddObject(1, ObjectType_Bitmap)
+
+	if err := m.SetPropertyValueU16(1, Width)(100); err != nil {
+		t.Fatalf("set U16: %s", err)
+	}
+
+	err := m.SetPropertyValueU16(2, Width)(100)
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound, got %v", err)
+	}
+	if errors.Is(err, ErrInvalidProperty) {
+		t.Errorf("ErrObjectNotFound matches ErrInvalidProperty")
+	}
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expected *StatusError, got %T", err)
+	}
+	if statusErr.Code != StatusInvalidObjectID || !bytes.Equal(statusErr.SubCmd, []byte{0x01, 0x06}) {
+		t.Errorf("status error: %+v", statusErr)
+	}
+
+	if err := m.CreateObject(1, ObjectType_Bitmap); !errors.Is(err, ErrObjectIDInUse) {
+		t.Errorf("expected ErrObjectIDInUse, got %v", err)
+	}
+}
+
+func TestEmulatorEvents(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	events, err := m.Events()
+	if err != nil {
+		t.Fatalf("events: %s", err)
+	}
+
+	e.InjectEvent(0x15, 5, []byte{0x01})
+	e.InjectRegionTouch(0x01, 7)
+
+	tests := []struct {
+		typ   EventType
+		objID uint16
+		value []byte
+	}{
+		{ButtonClick, 5, []byte{0x01}},
+		{RegionTouch, 7, []byte{0x01}},
+	}
+	for _, tt := range tests {
+		select {
+		case evt := <-events:
+			if evt.Type != tt.typ || evt.ObjId != tt.objID || !bytes.Equal(evt.Value, tt.value) {
+				t.Errorf("event: %+v, want %+v", evt, tt)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting event %+v", tt)
+		}
+	}
+}
+
+func TestEmulatorCreateObjects(t *testing.T) {
+	m, e := newEmulatedDisplay(t, &emulator.Options{Strict: true})
+	for i, typ := range ObjectTypes() {
+		if err := m.CreateObject(10+i, typ.Type); err != nil {
+			t.Fatalf("create %s: %s", typ.Name, err)
+		}
+		// FE FA 01 00, type, id
+		requests := e.Requests()
+		req := requests[len(requests)-1]
+		if len(req) != 8 || !bytes.Equal(req[4:6], typ.Type) || int(req[7]) != 10+i {
+			t.Errorf("create %s: [% X]", typ.Name, req)
+		}
+	}
+	if err := m.SetPropertyText(10, LabelText)("hi"); err != nil {
+		t.Errorf("set label text: %s", err)
+	}
+	if err := m.CreateObject(11, ObjectType_Label); !errors.Is(err, ErrObjectIDInUse) {
+		t.Errorf("expected ErrObjectIDInUse, got %v", err)
+	}
+}
+
+func TestRecvLongReply(t *testing.T) {
+	m, _ := newEmulatedDisplay(t, nil)
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), 160)
+	if err := m.WriteScratch(0, data); err != nil {
+		t.Fatalf("write scratch: %s", err)
+	}
+	// The reply is longer than a read: nothing read beyond it may be lost.
+	res, err := m.ReadScratch(0, len(data))
+	if err != nil {
+		t.Fatalf("read scratch: %s", err)
+	}
+	if !bytes.Equal(res, data) {
+		t.Errorf("read scratch: %d bytes, want %d", len(res), len(data))
+	}
+	if _, err := m.Version(); err != nil {
+		t.Errorf("version after long reply: %s", err)
+	}
+
+	// The whole scratch memory: the payload is 2 bytes longer than it.
+	full := bytes.Repeat([]byte{0xA5}, 4096)
+	if err := m.WriteScratch(0, full); err != nil {
+		t.Fatalf("write scratch: %s", err)
+	}
+	for _, listen := range []bool{false, true} {
+		if listen {
+			if err := m.Listen(); err != nil {
+				t.Fatalf("listen: %s", err)
+			}
+		}
+		res, err := m.ReadScratch(0, len(full))
+		if err != nil {
+			t.Fatalf("read full scratch, listen=%v: %s", listen, err)
+		}
+		if !bytes.Equal(res, full) {
+			t.Errorf("read full scratch, listen=%v: %d bytes", listen, len(res))
+		}
+	}
+}
+
+func TestRecvResyncAfterGlitch(t *testing.T) {
+	for _, listen := range []bool{false, true} {
+		t.Run(fmt.Sprintf("listen=%v", listen), func(t *testing.T) {
+			m, e := newEmulatedDisplay(t, &emulator.Options{Version: []byte{0x02, 0x05}})
+			if listen {
+				if err := m.Listen(); err != nil {
+					t.Fatalf("listen: %s", err)
+				}
+			}
+			// Line noise: a header waiting for 0x0F00 bytes that never come.
+			if err := e.Inject([]byte{0xFC, 0x12, 0x0F, 0x00}); err != nil {
+				t.Fatalf("inject: %s", err)
+			}
+			for i := 0; i < 3; i++ {
+				res, err := m.Version()
+				if err != nil {
+					t.Fatalf("version %d: %s", i, err)
+				}
+				if !bytes.Equal(res, []byte{0x02, 0x05}) {
+					t.Errorf("version %d: [% X]", i, res)
+				}
+			}
+		})
+	}
+}
@@ -34,7 +34,11 @@ func (m *display) UpdateBargraphValue(id, value int) ([]byte, error) {
 
 	data = append(data, valueb...)
 
-	return m.SendRecvCmd(0x69, data)
+	res, err := m.SendRecvCmd(0x69, data)
+	if err != nil {
+		return nil, err
+	}
+	return res.Payload, nil
 }
 
 //Update value in trace object
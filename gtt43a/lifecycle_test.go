@@ -0,0 +1,130 @@
+package gtt43a
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
+)
+
+func TestCloseDuringEvents(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		m, e := newEmulatedDisplay(t, nil)
+		if err := m.Listen(); err != nil {
+			t.Fatalf("listen: %s", err)
+		}
+		events, err := m.Events()
+		if err != nil {
+			t.Fatalf("events: %s", err)
+		}
+
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				e.InjectEvent(0x15, 1, []byte{0x01})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				m.Echo([]byte("x"))
+				m.State()
+			}
+		}()
+		consumed := make(chan struct{})
+		go func() {
+			defer wg.Done()
+			defer close(consumed)
+			for range events {
+			}
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		var closers sync.WaitGroup
+		for j := 0; j < 3; j++ {
+			closers.Add(1)
+			go func() {
+				defer closers.Done()
+				m.Close()
+			}()
+		}
+		closers.Wait()
+		if s := m.State(); s != CLOSED {
+			t.Errorf("state after close: %s", s)
+		}
+		select {
+		case <-consumed:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("events channel not closed")
+		}
+		close(stop)
+		wg.Wait()
+	}
+}
+
+func TestLifecycleReopen(t *testing.T) {
+	var mux sync.Mutex
+	emulators := make([]*emulator.Emulator, 0)
+	dial := func() (io.ReadWriteCloser, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		e := emulator.New(nil)
+		emulators = append(emulators, e)
+		return e.Port(), nil
+	}
+	defer func() {
+		for _, e := range emulators {
+			e.Close()
+		}
+	}()
+
+	m := NewDisplayWithDialer(dial, &PortOptions{ReadTimeout: emulatorReadTimeout})
+	for i := 0; i < 5; i++ {
+		if err := m.Open(); err != nil {
+			t.Fatalf("open: %s", err)
+		}
+		if err := m.Listen(); err != nil {
+			t.Fatalf("listen: %s", err)
+		}
+		if err := m.Listen(); err == nil {
+			t.Errorf("second listen accepted")
+		}
+		if _, err := m.Echo([]byte("a")); err != nil {
+			t.Errorf("echo listening: %s", err)
+		}
+		m.StopListen()
+		if s := m.State(); s != OPENED {
+			t.Errorf("state after stop listen: %s", s)
+		}
+		if _, err := m.Echo([]byte("b")); err != nil {
+			t.Errorf("echo opened: %s", err)
+		}
+		if err := m.Listen(); err != nil {
+			t.Fatalf("listen again: %s", err)
+		}
+		if err := m.Close(); err != nil {
+			t.Errorf("close: %s", err)
+		}
+		if s := m.State(); s != CLOSED {
+			t.Errorf("state after close: %s", s)
+		}
+		if _, err := m.Echo([]byte("c")); err == nil {
+			t.Errorf("echo accepted after close")
+		}
+	}
+}
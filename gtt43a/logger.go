@@ -0,0 +1,103 @@
+package gtt43a
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Logger receives the log messages of the display, with structured fields
+// as alternating key/value pairs ("dir", "tx", "cmd", 0xFA, "data", ...).
+// A *slog.Logger satisfies this interface. The default logger is silent.
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// Level of a log message, for NewStdLogger.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  Level
+}
+
+// NewStdLogger returns a Logger that writes the messages at or above level
+// to l (log.Default() if nil), as "LEVEL msg key=value ...".
+func NewStdLogger(l *log.Logger, level Level) Logger {
+	if l == nil {
+		l = log.Default()
+	}
+	return &stdLogger{logger: l, level: level}
+}
+
+func (s *stdLogger) Debug(msg string, args ...interface{}) { s.log(LevelDebug, msg, args) }
+func (s *stdLogger) Info(msg string, args ...interface{})  { s.log(LevelInfo, msg, args) }
+func (s *stdLogger) Warn(msg string, args ...interface{})  { s.log(LevelWarn, msg, args) }
+func (s *stdLogger) Error(msg string, args ...interface{}) { s.log(LevelError, msg, args) }
+
+func (s *stdLogger) log(level Level, msg string, args []interface{}) {
+	if level < s.level {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString(level.String())
+	sb.WriteString(" ")
+	sb.WriteString(msg)
+	for i := 0; i < len(args); i += 2 {
+		if i+1 < len(args) {
+			fmt.Fprintf(&sb, " %v=%v", args[i], args[i+1])
+		} else {
+			fmt.Fprintf(&sb, " %v", args[i])
+		}
+	}
+	s.logger.Print(sb.String())
+}
+
+// hexBytes formats data for the "data" field of the log messages.
+type hexBytes []byte
+
+func (h hexBytes) String() string {
+	return fmt.Sprintf("[% X]", []byte(h))
+}
+
+func (h hexBytes) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+func (m *display) logger() Logger {
+	if m.options.Logger == nil {
+		return nopLogger{}
+	}
+	return m.options.Logger
+}
@@ -0,0 +1,105 @@
+package gtt43a
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
+)
+
+type recordLogger struct {
+	mux     sync.Mutex
+	records []string
+}
+
+func (r *recordLogger) add(level, msg string, args []interface{}) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	fields := make([]string, 0)
+	for _, v := range args {
+		fields = append(fields, fmt.Sprint(v))
+	}
+	r.records = append(r.records, level+" "+msg+" "+strings.Join(fields, " "))
+}
+
+func (r *recordLogger) Debug(msg string, args ...interface{}) { r.add("DEBUG", msg, args) }
+func (r *recordLogger) Info(msg string, args ...interface{})  { r.add("INFO", msg, args) }
+func (r *recordLogger) Warn(msg string, args ...interface{})  { r.add("WARN", msg, args) }
+func (r *recordLogger) Error(msg string, args ...interface{}) { r.add("ERROR", msg, args) }
+
+func (r *recordLogger) contains(s string) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for _, v := range r.records {
+		if strings.Contains(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLogger(t *testing.T) {
+	e := emulator.New(nil)
+	defer e.Close()
+
+	logger := &recordLogger{}
+	m := NewDisplayWithTransport(e.Port(), &PortOptions{ReadTimeout: emulatorReadTimeout, Logger: logger})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer m.Close()
+
+	if _, err := m.Echo([]byte{0x01, 0x02}); err != nil {
+		t.Fatalf("echo: %s", err)
+	}
+	if !logger.contains("DEBUG request dir tx cmd [FF] data [01 02]") {
+		t.Errorf("request not logged: %q", logger.records)
+	}
+	if !logger.contains("DEBUG read dir rx data [FC FF 00 02 01 02]") {
+		t.Errorf("response not logged: %q", logger.records)
+	}
+}
+
+func TestStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewStdLogger(log.New(&buf, "", 0), LevelWarn)
+
+	logger.Debug("request", "dir", "tx")
+	logger.Warn("response dropped", "cmd", hexBytes{0xFA}, "data", hexBytes{0x01, 0x06, 0xFE})
+
+	want := "WARN response dropped cmd=[FA] data=[01 06 FE]\n"
+	if buf.String() != want {
+		t.Errorf("output: %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLoggerBadEvent(t *testing.T) {
+	e := emulator.New(nil)
+	defer e.Close()
+
+	logger := &recordLogger{}
+	m := NewDisplayWithTransport(e.Port(), &PortOptions{ReadTimeout: emulatorReadTimeout, Logger: logger})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer m.Close()
+	events, cancel := m.Subscribe(EventFilter{})
+	defer cancel()
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+
+	e.Inject([]byte{0xFC, 0x86, 0x00, 0x03, 0x01, 0x00, 0x10})
+	e.Inject([]byte{0xFC, 0x87, 0x00, 0x03, 0x01, 0x04, 0x00})
+	evt := nextEvent(t, events)
+	if evt.Type != RegionTouch || evt.ObjId != 4 {
+		t.Errorf("event: %+v", evt)
+	}
+	if !logger.contains("WARN bad event dropped cmd [86] data [01 00 10]") {
+		t.Errorf("bad event not logged: %q", logger.records)
+	}
+}
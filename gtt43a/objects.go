@@ -6,8 +6,49 @@ func (typeP GTT25ObjectType) Value() []byte {
 	return []byte(typeP)
 }
 
+// GTT2.5 object types, for CreateObject. The low byte is also the first byte
+// of the properties and methods of the type (LabelText is 09 06, SliderValue
+// 0A 08, ObjectList_Get 1A 03). Bargraph, Trace, Animation, Region, Keypad
+// and Image have no documented ID yet.
+var ObjectType_Gauge GTT25ObjectType = []byte{0x00, 0x03}
+var ObjectType_Label GTT25ObjectType = []byte{0x00, 0x09}
+var ObjectType_Slider GTT25ObjectType = []byte{0x00, 0x0a}
 var ObjectType_Bitmap GTT25ObjectType = []byte{0x00, 0x0d}
+var ObjectType_Button GTT25ObjectType = []byte{0x00, 0x15}
+var ObjectType_Toggle GTT25ObjectType = []byte{0x00, 0x16}
+var ObjectType_ObjectList GTT25ObjectType = []byte{0x00, 0x1a}
 var ObjectType_VisualBitmap GTT25ObjectType = []byte{0x00, 0x1f}
 
+// ObjectType_Listbox is the list of selectable items of GTT Designer.
+var ObjectType_Listbox = ObjectType_ObjectList
+
+// ObjectType_VisualObject is the abstract base of the visual objects, the
+// type of their common properties (Left, Top, Enabled, ...). It can't be
+// created.
+var ObjectType_VisualObject GTT25ObjectType = []byte{0x00, 0x02}
+
+// ObjectTypeInfo names an object type of the catalogue.
+type ObjectTypeInfo struct {
+	Type GTT25ObjectType
+	Name string
+}
+
+var objectTypes = []ObjectTypeInfo{
+	{ObjectType_Gauge, "Gauge"},
+	{ObjectType_Label, "Label"},
+	{ObjectType_Slider, "Slider"},
+	{ObjectType_Bitmap, "Bitmap"},
+	{ObjectType_Button, "Button"},
+	{ObjectType_Toggle, "Toggle"},
+	{ObjectType_ObjectList, "ObjectList"},
+	{ObjectType_VisualBitmap, "VisualBitmap"},
+}
+
+// ObjectTypes returns the catalogue of the object types that CreateObject
+// can create.
+func ObjectTypes() []ObjectTypeInfo {
+	return append([]ObjectTypeInfo{}, objectTypes...)
+}
+
 const textEncoding_ASCII = 1
 const textEncoding_UTF8 = 2
@@ -0,0 +1,108 @@
+package gtt43a
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
+)
+
+func TestResponseTimeoutOption(t *testing.T) {
+	e := emulator.New(&emulator.Options{Latency: 150 * time.Millisecond})
+	defer e.Close()
+
+	m := NewDisplayWithTransport(e.Port(), &PortOptions{ReadTimeout: emulatorReadTimeout, ResponseTimeout: 50 * time.Millisecond})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	if _, err := m.Version(); !errors.Is(err, ErrorDevTimeout) {
+		t.Errorf("expected ErrorDevTimeout, got %v", err)
+	}
+	m.Close()
+
+	e = emulator.New(&emulator.Options{Latency: 150 * time.Millisecond})
+	defer e.Close()
+	m = NewDisplayWithTransport(e.Port(), &PortOptions{ReadTimeout: emulatorReadTimeout, ResponseTimeout: time.Second})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer m.Close()
+	if err := m.RunScript("Screen1.bin"); err != nil {
+		t.Errorf("run script: %s", err)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	e := emulator.New(nil)
+	defer e.Close()
+
+	m := NewDisplayWithTransport(e.Port(), &PortOptions{
+		ReadTimeout:     emulatorReadTimeout,
+		ResponseTimeout: 100 * time.Millisecond,
+		Retry:           &RetryPolicy{Count: 2, Backoff: 10 * time.Millisecond},
+	})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer m.Close()
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+
+	e.Drop(2)
+	if _, err := m.GetPropertyValueU16(1, Width)(); err != nil {
+		t.Fatalf("get U16: %s", err)
+	}
+	if n := len(e.Requests()); n != 3 {
+		t.Errorf("requests: %d, want 3", n)
+	}
+
+	// Setters are not idempotent, they are never retried.
+	e.Drop(1)
+	if err := m.SetPropertyValueU16(1, Width)(10); !errors.Is(err, ErrorDevTimeout) {
+		t.Errorf("expected ErrorDevTimeout, got %v", err)
+	}
+	if n := len(e.Requests()); n != 4 {
+		t.Errorf("requests: %d, want 4", n)
+	}
+}
+
+func TestRetryPolicyContextDeadline(t *testing.T) {
+	e := emulator.New(nil)
+	defer e.Close()
+
+	m := NewDisplayWithTransport(e.Port(), &PortOptions{
+		ReadTimeout:     emulatorReadTimeout,
+		ResponseTimeout: 100 * time.Millisecond,
+		Retry:           &RetryPolicy{Count: 2, Backoff: 10 * time.Millisecond},
+	})
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	defer m.Close()
+
+	// The caller deadline covers the retries, every attempt is bounded by
+	// the response timeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	e.Drop(2)
+	if _, err := m.GetPropertyValueU16Context(ctx, 1, Width)(); err != nil {
+		t.Fatalf("get U16: %s", err)
+	}
+	if n := len(e.Requests()); n != 3 {
+		t.Errorf("requests: %d, want 3", n)
+	}
+
+	// A deadline shorter than the response timeout stops the retries.
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	e.Drop(3)
+	if _, err := m.GetPropertyValueU16Context(ctx, 1, Width)(); !errors.Is(err, ErrorDevTimeout) {
+		t.Errorf("expected ErrorDevTimeout, got %v", err)
+	}
+	if n := len(e.Requests()); n != 4 {
+		t.Errorf("requests: %d, want 4", n)
+	}
+}
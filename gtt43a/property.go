@@ -1,8 +1,8 @@
 package gtt43a
 
 import (
+	"context"
 	"encoding/binary"
-	"fmt"
 	"unicode/utf16"
 )
 
@@ -37,6 +37,10 @@ func (typeP GTT25PropertyType) Value() []byte {
 	return []byte(typeP)
 }
 
+// The property requests are FE FA 01, the method, the object ID and the
+// property. Methods go in set/get pairs, set even and get odd: U8 04/05,
+// U16 06/07, S16 08/09, Text 0A/0B, S32 0C/0D, Bool 0E/0F.
+
 func ApduSetPropertyValueU16(id int, prpType GTT25PropertyType, value int) []byte {
 	data := []byte{0xFE, 0xFA, 0x01, 0x06}
 	idb := make([]byte, 2)
@@ -51,19 +55,17 @@ func ApduSetPropertyValueU16(id int, prpType GTT25PropertyType, value int) []byt
 
 //Set Property ValueU16 GTT25Object
 func (m *display) SetPropertyValueU16(id int, prpType GTT25PropertyType) func(value int) error {
+	return m.SetPropertyValueU16Context(context.Background(), id, prpType)
+}
+
+//Set Property ValueU16 GTT25Object, bounded by ctx
+func (m *display) SetPropertyValueU16Context(ctx context.Context, id int, prpType GTT25PropertyType) func(value int) error {
 	return func(value int) error {
 		data := ApduSetPropertyValueU16(id, prpType, value)
 		/**/
-		res, err := m.SendRecv(data)
-		if err != nil {
+		if _, err := m.sendRecvObject(ctx, data); err != nil {
 			return err
 		}
-		if len(res) < 3 {
-			return fmt.Errorf("error in response: [% X]", res)
-		}
-		if res[len(res)-1] != byte(0xFE) {
-			return fmt.Errorf("error in request U16, status code: [%X]", res[2])
-		}
 		/**/
 		return nil
 	}
@@ -83,19 +85,17 @@ func ApduSetPropertyValueS16(id int, prpType GTT25PropertyType, value int) []byt
 
 //Set Property ValueS16 GTT25Object
 func (m *display) SetPropertyValueS16(id int, prpType GTT25PropertyType) func(value int) error {
+	return m.SetPropertyValueS16Context(context.Background(), id, prpType)
+}
+
+//Set Property ValueS16 GTT25Object, bounded by ctx
+func (m *display) SetPropertyValueS16Context(ctx context.Context, id int, prpType GTT25PropertyType) func(value int) error {
 	return func(value int) error {
 		data := ApduSetPropertyValueS16(id, prpType, value)
 		/**/
-		res, err := m.SendRecv(data)
-		if err != nil {
+		if _, err := m.sendRecvObject(ctx, data); err != nil {
 			return err
 		}
-		if len(res) < 3 {
-			return fmt.Errorf("error in response: [% X]", res)
-		}
-		if res[len(res)-1] != byte(0xFE) {
-			return fmt.Errorf("error in request S16, status code: [%X]", res[2])
-		}
 		/**/
 		return nil
 	}
@@ -113,19 +113,17 @@ func ApduSetPropertyValueU8(id int, prpType GTT25PropertyType, value int) []byte
 
 //Set Property ValueU8 GTT25Object
 func (m *display) SetPropertyValueU8(id int, prpType GTT25PropertyType) func(value int) error {
+	return m.SetPropertyValueU8Context(context.Background(), id, prpType)
+}
+
+//Set Property ValueU8 GTT25Object, bounded by ctx
+func (m *display) SetPropertyValueU8Context(ctx context.Context, id int, prpType GTT25PropertyType) func(value int) error {
 	return func(value int) error {
 		data := ApduSetPropertyValueU8(id, prpType, value)
 		/**/
-		res, err := m.SendRecv(data)
-		if err != nil {
+		if _, err := m.sendRecvObject(ctx, data); err != nil {
 			return err
 		}
-		if len(res) < 3 {
-			return fmt.Errorf("error in response: [% X]", res)
-		}
-		if res[len(res)-1] != byte(0xFE) {
-			return fmt.Errorf("error in request U8, status code: [%X], [% X]", res[2], res)
-		}
 		/**/
 		return nil
 	}
@@ -154,26 +152,24 @@ func ApduSetPropertyText(id int, prpType GTT25PropertyType, text string) []byte
 
 //Set Property Text GTT25Object
 func (m *display) SetPropertyText(id int, prpType GTT25PropertyType) func(text string) error {
+	return m.SetPropertyTextContext(context.Background(), id, prpType)
+}
+
+//Set Property Text GTT25Object, bounded by ctx
+func (m *display) SetPropertyTextContext(ctx context.Context, id int, prpType GTT25PropertyType) func(text string) error {
 	return func(text string) error {
 		data := ApduSetPropertyText(id, prpType, text)
 		/**/
-		res, err := m.SendRecv(data)
-		if err != nil {
+		if _, err := m.sendRecvObject(ctx, data); err != nil {
 			return err
 		}
-		if len(res) < 3 {
-			return fmt.Errorf("error in response: [% X]", res)
-		}
-		if res[len(res)-1] != byte(0xFE) {
-			return fmt.Errorf("error in request, status code: [%X]", res[2])
-		}
 		/**/
 		return nil
 	}
 }
 
 func ApduGetPropertyText(id int, prpType GTT25PropertyType) []byte {
-	data := []byte{0xFE, 0xFA, 0x01, 0x09}
+	data := []byte{0xFE, 0xFA, 0x01, 0x0B}
 	idb := make([]byte, 2)
 	binary.BigEndian.PutUint16(idb, uint16(id))
 	data = append(data, idb...)
@@ -182,21 +178,23 @@ func ApduGetPropertyText(id int, prpType GTT25PropertyType) []byte {
 }
 
 //Get Property Text GTT25Object
-func (m *display) GetPropertyText(id int, prpType GTT25PropertyType) func() ([]byte, error) {
-	return func() ([]byte, error) {
+func (m *display) GetPropertyText(id int, prpType GTT25PropertyType) func() (string, error) {
+	return m.GetPropertyTextContext(context.Background(), id, prpType)
+}
+
+//Get Property Text GTT25Object, bounded by ctx
+func (m *display) GetPropertyTextContext(ctx context.Context, id int, prpType GTT25PropertyType) func() (string, error) {
+	return func() (string, error) {
 		data := ApduGetPropertyText(id, prpType)
-		res, err := m.SendRecv(data)
+		var res *Response
+		err := m.retry(ctx, func(ctx context.Context) (err error) {
+			res, err = m.sendRecvObject(ctx, data)
+			return err
+		})
 		if err != nil {
-			return nil, err
+			return "", err
 		}
-		if len(res) < 3 {
-			return nil, fmt.Errorf("error in response: [% X]", res)
-		}
-		if res[len(res)-3] != byte(0xFE) {
-			return nil, fmt.Errorf("error in request GetText, status code: [%X]", res[2])
-		}
-
-		return res[len(res)-2:], nil
+		return res.Text()
 	}
 }
 
@@ -210,21 +208,23 @@ func ApduGetPropertyValueU16(id int, prpType GTT25PropertyType) []byte {
 }
 
 //Get Property ValueU16 GTT25Object
-func (m *display) GetPropertyValueU16(id int, prpType GTT25PropertyType) func() ([]byte, error) {
-	return func() ([]byte, error) {
+func (m *display) GetPropertyValueU16(id int, prpType GTT25PropertyType) func() (uint16, error) {
+	return m.GetPropertyValueU16Context(context.Background(), id, prpType)
+}
+
+//Get Property ValueU16 GTT25Object, bounded by ctx
+func (m *display) GetPropertyValueU16Context(ctx context.Context, id int, prpType GTT25PropertyType) func() (uint16, error) {
+	return func() (uint16, error) {
 		data := ApduGetPropertyValueU16(id, prpType)
-		res, err := m.SendRecv(data)
+		var res *Response
+		err := m.retry(ctx, func(ctx context.Context) (err error) {
+			res, err = m.sendRecvObject(ctx, data)
+			return err
+		})
 		if err != nil {
-			return nil, err
-		}
-		if len(res) < 3 {
-			return nil, fmt.Errorf("error in response: [% X]", res)
-		}
-		if res[len(res)-3] != byte(0xFE) {
-			return nil, fmt.Errorf("error in request U16, status code: [%X]", res[2])
+			return 0, err
 		}
-
-		return res[len(res)-2:], nil
+		return res.Uint16()
 	}
 }
 
@@ -238,24 +238,35 @@ func ApduGetPropertyValueS16(id int, prpType GTT25PropertyType) []byte {
 }
 
 //Get Property ValueS16 GTT25Object
-func (m *display) GetPropertyValueS16(id int, prpType GTT25PropertyType) func() ([]byte, error) {
-	return func() ([]byte, error) {
+func (m *display) GetPropertyValueS16(id int, prpType GTT25PropertyType) func() (int16, error) {
+	return m.GetPropertyValueS16Context(context.Background(), id, prpType)
+}
+
+//Get Property ValueS16 GTT25Object, bounded by ctx
+func (m *display) GetPropertyValueS16Context(ctx context.Context, id int, prpType GTT25PropertyType) func() (int16, error) {
+	return func() (int16, error) {
 		data := ApduGetPropertyValueS16(id, prpType)
-		res, err := m.SendRecv(data)
+		var res *Response
+		err := m.retry(ctx, func(ctx context.Context) (err error) {
+			res, err = m.sendRecvObject(ctx, data)
+			return err
+		})
 		if err != nil {
-			return nil, err
-		}
-		if len(res) < 3 {
-			return nil, fmt.Errorf("error in response: [% X]", res)
-		}
-		if res[len(res)-3] != byte(0xFE) {
-			return nil, fmt.Errorf("error in request S16, status code: [%X]", res[2])
+			return 0, err
 		}
-
-		return res[len(res)-2:], nil
+		return res.Int16()
 	}
 }
 
+func ApduGetPropertyValueS32(id int, prpType GTT25PropertyType) []byte {
+	data := []byte{0xFE, 0xFA, 0x01, 0x0D}
+	idb := make([]byte, 2)
+	binary.BigEndian.PutUint16(idb, uint16(id))
+	data = append(data, idb...)
+	data = append(data, prpType.Value()...)
+	return data
+}
+
 func ApduGetPropertyValueU8(id int, prpType GTT25PropertyType) []byte {
 	data := []byte{0xFE, 0xFA, 0x01, 0x05}
 	idb := make([]byte, 2)
@@ -267,18 +278,138 @@ func ApduGetPropertyValueU8(id int, prpType GTT25PropertyType) []byte {
 
 //Get Property ValueU8 GTT25Object
 func (m *display) GetPropertyValueU8(id int, prpType GTT25PropertyType) func() (byte, error) {
+	return m.GetPropertyValueU8Context(context.Background(), id, prpType)
+}
+
+//Get Property ValueU8 GTT25Object, bounded by ctx
+func (m *display) GetPropertyValueU8Context(ctx context.Context, id int, prpType GTT25PropertyType) func() (byte, error) {
 	return func() (byte, error) {
 		data := ApduGetPropertyValueU8(id, prpType)
-		res, err := m.SendRecv(data)
+		var res *Response
+		err := m.retry(ctx, func(ctx context.Context) (err error) {
+			res, err = m.sendRecvObject(ctx, data)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		return res.Uint8()
+	}
+}
+
+// ApduSetPropertyValueS32 keeps the low 32 bits of value, SetPropertyValueS32
+// rejects the values out of the S32 range.
+func ApduSetPropertyValueS32(id int, prpType GTT25PropertyType, value int) []byte {
+	data := []byte{0xFE, 0xFA, 0x01, 0x0C}
+	idb := make([]byte, 2)
+	binary.BigEndian.PutUint16(idb, uint16(id))
+	valueb := make([]byte, 4)
+	binary.BigEndian.PutUint32(valueb, uint32(int32(value)))
+	data = append(data, idb...)
+	data = append(data, prpType.Value()...)
+	data = append(data, valueb...)
+	return data
+}
+
+//Set Property ValueS32 GTT25Object
+func (m *display) SetPropertyValueS32(id int, prpType GTT25PropertyType) func(value int) error {
+	return m.SetPropertyValueS32Context(context.Background(), id, prpType)
+}
+
+//Set Property ValueS32 GTT25Object, bounded by ctx
+func (m *display) SetPropertyValueS32Context(ctx context.Context, id int, prpType GTT25PropertyType) func(value int) error {
+	return func(value int) error {
+		if !fitsS32(int64(value)) {
+			return &PropertyValueError{Property: prpType, Type: PropertyS32, Value: value}
+		}
+		data := ApduSetPropertyValueS32(id, prpType, value)
+		if _, err := m.sendRecvObject(ctx, data); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+//Get Property ValueS32 GTT25Object
+func (m *display) GetPropertyValueS32(id int, prpType GTT25PropertyType) func() (int32, error) {
+	return m.GetPropertyValueS32Context(context.Background(), id, prpType)
+}
+
+//Get Property ValueS32 GTT25Object, bounded by ctx
+func (m *display) GetPropertyValueS32Context(ctx context.Context, id int, prpType GTT25PropertyType) func() (int32, error) {
+	return func() (int32, error) {
+		data := ApduGetPropertyValueS32(id, prpType)
+		var res *Response
+		err := m.retry(ctx, func(ctx context.Context) (err error) {
+			res, err = m.sendRecvObject(ctx, data)
+			return err
+		})
 		if err != nil {
 			return 0, err
 		}
-		if len(res) < 3 {
-			return 0x00, fmt.Errorf("error in response: [% X]", res)
+		return res.Int32()
+	}
+}
+
+func ApduSetPropertyBool(id int, prpType GTT25PropertyType, value bool) []byte {
+	data := []byte{0xFE, 0xFA, 0x01, 0x0E}
+	idb := make([]byte, 2)
+	binary.BigEndian.PutUint16(idb, uint16(id))
+	data = append(data, idb...)
+	data = append(data, prpType.Value()...)
+	data = append(data, byte(boolToInt(value)))
+	return data
+}
+
+func ApduGetPropertyBool(id int, prpType GTT25PropertyType) []byte {
+	data := []byte{0xFE, 0xFA, 0x01, 0x0F}
+	idb := make([]byte, 2)
+	binary.BigEndian.PutUint16(idb, uint16(id))
+	data = append(data, idb...)
+	data = append(data, prpType.Value()...)
+	return data
+}
+
+//Set Property Bool GTT25Object
+func (m *display) SetPropertyBool(id int, prpType GTT25PropertyType) func(value bool) error {
+	return m.SetPropertyBoolContext(context.Background(), id, prpType)
+}
+
+//Set Property Bool GTT25Object, bounded by ctx
+func (m *display) SetPropertyBoolContext(ctx context.Context, id int, prpType GTT25PropertyType) func(value bool) error {
+	return func(value bool) error {
+		data := ApduSetPropertyBool(id, prpType, value)
+		if _, err := m.sendRecvObject(ctx, data); err != nil {
+			return err
 		}
-		if res[len(res)-2] != byte(0xFE) {
-			return byte(0x00), fmt.Errorf("error in request GetU8, status code: [%X]", res[2])
+		return nil
+	}
+}
+
+//Get Property Bool GTT25Object
+func (m *display) GetPropertyBool(id int, prpType GTT25PropertyType) func() (bool, error) {
+	return m.GetPropertyBoolContext(context.Background(), id, prpType)
+}
+
+//Get Property Bool GTT25Object, bounded by ctx
+func (m *display) GetPropertyBoolContext(ctx context.Context, id int, prpType GTT25PropertyType) func() (bool, error) {
+	return func() (bool, error) {
+		data := ApduGetPropertyBool(id, prpType)
+		var res *Response
+		err := m.retry(ctx, func(ctx context.Context) (err error) {
+			res, err = m.sendRecvObject(ctx, data)
+			return err
+		})
+		if err != nil {
+			return false, err
 		}
-		return res[len(res)-1], nil
+		return res.Bool()
+	}
+}
+
+func boolToInt(v bool) int {
+	if v {
+		return 1
 	}
+	return 0
 }
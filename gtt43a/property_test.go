@@ -0,0 +1,138 @@
+package gtt43a
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+// s32Property is known to the emulator only: none of the documented
+// properties is S32.
+var s32Property GTT25PropertyType = []byte{0x7E, 0x00}
+
+// registerProperty adds info to the registry for the test.
+func registerProperty(t *testing.T, info PropertyInfo) {
+	saved := propertyRegistry
+	propertyRegistry = append(append([]PropertyInfo{}, saved...), info)
+	t.Cleanup(func() {
+		propertyRegistry = saved
+	})
+}
+
+func TestPropertyGetterMethods(t *testing.T) {
+	methods := make(map[string]string)
+	for name, v := range map[string]struct {
+		data   []byte
+		method byte
+	}{
+		"SetU8":   {ApduSetPropertyValueU8(1, Width, 0), 0x04},
+		"GetU8":   {ApduGetPropertyValueU8(1, Width), 0x05},
+		"SetU16":  {ApduSetPropertyValueU16(1, Width, 0), 0x06},
+		"GetU16":  {ApduGetPropertyValueU16(1, Width), 0x07},
+		"SetS16":  {ApduSetPropertyValueS16(1, Width, 0), 0x08},
+		"GetS16":  {ApduGetPropertyValueS16(1, Width), 0x09},
+		"SetText": {ApduSetPropertyText(1, Width, ""), 0x0A},
+		"GetText": {ApduGetPropertyText(1, Width), 0x0B},
+		"SetS32":  {ApduSetPropertyValueS32(1, Width, 0), 0x0C},
+		"GetS32":  {ApduGetPropertyValueS32(1, Width), 0x0D},
+		"SetBool": {ApduSetPropertyBool(1, Width, false), 0x0E},
+		"GetBool": {ApduGetPropertyBool(1, Width), 0x0F},
+	} {
+		if v.data[3] != v.method {
+			t.Errorf("%s: method %02X, want %02X", name, v.data[3], v.method)
+		}
+		method := string(v.data[2:4])
+		if other, ok := methods[method]; ok {
+			t.Errorf("%s and %s share the method [% X]", name, other, v.data[2:4])
+		}
+		methods[method] = name
+	}
+}
+
+func TestPropertyRoundTrip(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+
+	for _, value := range []int{-32768, -1, 0, 1234, 32767} {
+		if _, err := m.SendRecv(ApduSetPropertyValueS16(1, Left, value)); err != nil {
+			t.Fatalf("set S16: %s", err)
+		}
+		got, err := m.GetPropertyValueS16(1, Left)()
+		if err != nil || int(got) != value {
+			t.Errorf("S16 %d: got %d, %v", value, got, err)
+		}
+	}
+
+	for _, text := range []string{"", "hi", "Cív", "温度 🌡"} {
+		if _, err := m.SendRecv(ApduSetPropertyText(1, LabelText, text)); err != nil {
+			t.Fatalf("set text: %s", err)
+		}
+		got, err := m.GetPropertyText(1, LabelText)()
+		if err != nil || got != text {
+			t.Errorf("text %q: got %q, %v", text, got, err)
+		}
+	}
+
+	e.SetProperty(1, s32Property, []byte{0xFF, 0xFE, 0x79, 0x60})
+	res, err := m.SendRecvContext(context.Background(), ApduGetPropertyValueS32(1, s32Property))
+	if err != nil {
+		t.Fatalf("get S32: %s", err)
+	}
+	if v, err := res.Int32(); err != nil || v != -100000 {
+		t.Errorf("S32: %d, %v", v, err)
+	}
+}
+
+func TestPropertyS32Bool(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+
+	for _, value := range []int{-2147483648, -100000, 0, 70000, 2147483647} {
+		if err := m.SetPropertyValueS32(1, s32Property)(value); err != nil {
+			t.Fatalf("set S32: %s", err)
+		}
+		got, err := m.GetPropertyValueS32(1, s32Property)()
+		if err != nil || int(got) != value {
+			t.Errorf("S32 %d: got %d, %v", value, got, err)
+		}
+	}
+
+	// out of the S32 range is rejected before sending
+	before := len(e.Requests())
+	for _, value := range []int64{-0x80000001, 0x80000000} {
+		var valueErr *PropertyValueError
+		if err := m.SetPropertyValueS32(1, s32Property)(int(value)); !errors.As(err, &valueErr) {
+			t.Errorf("S32 %d: expected PropertyValueError, got %v", value, err)
+		}
+	}
+	if n := len(e.Requests()) - before; n != 0 {
+		t.Errorf("S32 out of range: %d requests sent", n)
+	}
+
+	for _, value := range []bool{true, false} {
+		if err := m.SetPropertyBool(1, Enabled)(value); err != nil {
+			t.Fatalf("set bool: %s", err)
+		}
+		got, err := m.GetPropertyBool(1, Enabled)()
+		if err != nil || got != value {
+			t.Errorf("bool %v: got %v, %v", value, got, err)
+		}
+	}
+
+	registerProperty(t, PropertyInfo{Property: s32Property, Name: "S32", Object: ObjectType_VisualObject, Type: PropertyS32})
+	if err := m.SetProperty(2, s32Property, -500000); err != nil {
+		t.Fatalf("SetProperty S32: %s", err)
+	}
+	if v := e.Property(2, s32Property); !bytes.Equal(v, []byte{0xFF, 0xF8, 0x5E, 0xE0}) {
+		t.Errorf("S32 property: [% X]", v)
+	}
+	if err := m.SetProperty(2, CanFocus, true); err != nil {
+		t.Fatalf("SetProperty bool: %s", err)
+	}
+	if v := e.Property(2, CanFocus); !bytes.Equal(v, []byte{0x01}) {
+		t.Errorf("CanFocus: [% X]", v)
+	}
+	var valueErr *PropertyValueError
+	if err := m.SetProperty(2, CanFocus, 1); !errors.As(err, &valueErr) {
+		t.Errorf("int for Bool: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package gtt43a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+)
+
+// PropertyValueType is the wire type of a GTT2.5 property.
+type PropertyValueType int
+
+const (
+	PropertyU8 PropertyValueType = iota + 1
+	PropertyU16
+	PropertyS16
+	PropertyS32
+	PropertyText
+	PropertyBool
+	// PropertyColour is a colour channel, sent as U8. SetProperty takes the
+	// channel from a color.Color or the value of the channel.
+	PropertyColour
+)
+
+func (t PropertyValueType) String() string {
+	switch t {
+	case PropertyU8:
+		return "U8"
+	case PropertyU16:
+		return "U16"
+	case PropertyS16:
+		return "S16"
+	case PropertyS32:
+		return "S32"
+	case PropertyText:
+		return "Text"
+	case PropertyBool:
+		return "Bool"
+	case PropertyColour:
+		return "Colour"
+	}
+	return fmt.Sprintf("PropertyValueType(%d)", int(t))
+}
+
+type colourChannel int
+
+const (
+	channelR colourChannel = iota
+	channelG
+	channelB
+)
+
+// PropertyInfo describes a property of the registry.
+type PropertyInfo struct {
+	Property GTT25PropertyType
+	Name     string
+	// Object is the object type that declares the property,
+	// ObjectType_VisualObject for the properties of every visual object.
+	Object GTT25ObjectType
+	Type   PropertyValueType
+
+	channel colourChannel
+}
+
+// propertyRegistry holds the documented properties of property.go only: a
+// guessed ID would let SetProperty send a wrong command to a panel.
+var propertyRegistry = []PropertyInfo{
+	{Property: Invalidated, Name: "Invalidated", Object: ObjectType_VisualObject, Type: PropertyBool},
+	{Property: Left, Name: "Left", Object: ObjectType_VisualObject, Type: PropertyS16},
+	{Property: Top, Name: "Top", Object: ObjectType_VisualObject, Type: PropertyS16},
+	{Property: Width, Name: "Width", Object: ObjectType_VisualObject, Type: PropertyU16},
+	{Property: Height, Name: "Height", Object: ObjectType_VisualObject, Type: PropertyU16},
+	{Property: CanFocus, Name: "CanFocus", Object: ObjectType_VisualObject, Type: PropertyBool},
+	{Property: HasFocus, Name: "HasFocus", Object: ObjectType_VisualObject, Type: PropertyBool},
+	{Property: Enabled, Name: "Enabled", Object: ObjectType_VisualObject, Type: PropertyBool},
+	{Property: GaugeValue, Name: "GaugeValue", Object: ObjectType_Gauge, Type: PropertyS16},
+	{Property: LabelBackgroundR, Name: "LabelBackgroundR", Object: ObjectType_Label, Type: PropertyColour, channel: channelR},
+	{Property: LabelBackgroundG, Name: "LabelBackgroundG", Object: ObjectType_Label, Type: PropertyColour, channel: channelG},
+	{Property: LabelBackgroundB, Name: "LabelBackgroundB", Object: ObjectType_Label, Type: PropertyColour, channel: channelB},
+	{Property: LabelText, Name: "LabelText", Object: ObjectType_Label, Type: PropertyText},
+	{Property: LabelFontSize, Name: "LabelFontSize", Object: ObjectType_Label, Type: PropertyU8},
+	{Property: SliderValue, Name: "SliderValue", Object: ObjectType_Slider, Type: PropertyS16},
+	{Property: SliderLabelText, Name: "SliderLabelText", Object: ObjectType_Slider, Type: PropertyText},
+	{Property: ButtonText, Name: "ButtonText", Object: ObjectType_Button, Type: PropertyText},
+	{Property: ButtonState, Name: "ButtonState", Object: ObjectType_Button, Type: PropertyU8},
+	{Property: ButtonDisableBitmap, Name: "ButtonDisableBitmap", Object: ObjectType_Button, Type: PropertyU16},
+	{Property: VisualBitmap_Source, Name: "VisualBitmap_Source", Object: ObjectType_VisualBitmap, Type: PropertyU16},
+	{Property: VisualBitmap_SourceIndex, Name: "VisualBitmap_SourceIndex", Object: ObjectType_VisualBitmap, Type: PropertyU16},
+}
+
+// Properties returns the registry of the known properties.
+func Properties() []PropertyInfo {
+	return append([]PropertyInfo{}, propertyRegistry...)
+}
+
+// LookupProperty returns the registry entry of prpType.
+func LookupProperty(prpType GTT25PropertyType) (PropertyInfo, bool) {
+	for _, v := range propertyRegistry {
+		if string(v.Property) == string(prpType) {
+			return v, true
+		}
+	}
+	return PropertyInfo{}, false
+}
+
+var ErrUnknownProperty = errors.New("unknown property")
+
+// PropertyValueError is a value passed to SetProperty that doesn't match the
+// type of the property, or doesn't fit in it.
+type PropertyValueError struct {
+	Property GTT25PropertyType
+	Type     PropertyValueType
+	Value    interface{}
+}
+
+func (e *PropertyValueError) Error() string {
+	return fmt.Sprintf("property [% X]: %T value %v is not a valid %s", e.Property.Value(), e.Value, e.Value, e.Type)
+}
+
+// ApduSetProperty returns the request that sets prpType to value with the
+// method of the type of the property in the registry: int values for the
+// integer properties (any Go integer type), string for Text, bool for Bool,
+// color.Color or int for Colour.
+func ApduSetProperty(id int, prpType GTT25PropertyType, value interface{}) ([]byte, error) {
+	info, ok := LookupProperty(prpType)
+	if !ok {
+		return nil, fmt.Errorf("property [% X]: %w", prpType.Value(), ErrUnknownProperty)
+	}
+	valueErr := &PropertyValueError{Property: prpType, Type: info.Type, Value: value}
+	switch info.Type {
+	case PropertyText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, valueErr
+		}
+		return ApduSetPropertyText(id, prpType, text), nil
+	case PropertyBool:
+		v, ok := value.(bool)
+		if !ok {
+			return nil, valueErr
+		}
+		return ApduSetPropertyBool(id, prpType, v), nil
+	case PropertyColour:
+		if c, ok := value.(color.Color); ok {
+			rgba := color.RGBAModel.Convert(c).(color.RGBA)
+			value = int([]uint8{rgba.R, rgba.G, rgba.B}[info.channel])
+		}
+	}
+	v, ok := intValue(value)
+	if !ok {
+		return nil, valueErr
+	}
+	switch info.Type {
+	case PropertyU8, PropertyColour:
+		if v < 0 || v > 0xFF {
+			return nil, valueErr
+		}
+		return ApduSetPropertyValueU8(id, prpType, int(v)), nil
+	case PropertyU16:
+		if v < 0 || v > 0xFFFF {
+			return nil, valueErr
+		}
+		return ApduSetPropertyValueU16(id, prpType, int(v)), nil
+	case PropertyS16:
+		if v < -0x8000 || v > 0x7FFF {
+			return nil, valueErr
+		}
+		return ApduSetPropertyValueS16(id, prpType, int(v)), nil
+	case PropertyS32:
+		if !fitsS32(v) {
+			return nil, valueErr
+		}
+		return ApduSetPropertyValueS32(id, prpType, int(v)), nil
+	}
+	return nil, valueErr
+}
+
+// fitsS32 reports whether v fits in an S32 property.
+func fitsS32(v int64) bool {
+	return v >= -0x80000000 && v <= 0x7FFFFFFF
+}
+
+func intValue(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	}
+	return 0, false
+}
+
+// Set Property GTT25Object with the method of the type of the property, see
+// ApduSetProperty. Mismatched values are rejected before sending anything.
+func (m *display) SetProperty(id int, prpType GTT25PropertyType, value interface{}) error {
+	return m.SetPropertyContext(context.Background(), id, prpType, value)
+}
+
+// Set Property GTT25Object, bounded by ctx
+func (m *display) SetPropertyContext(ctx context.Context, id int, prpType GTT25PropertyType, value interface{}) error {
+	data, err := ApduSetProperty(id, prpType, value)
+	if err != nil {
+		return err
+	}
+	if _, err := m.sendRecvObject(ctx, data); err != nil {
+		return err
+	}
+	return nil
+}
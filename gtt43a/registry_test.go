@@ -0,0 +1,72 @@
+package gtt43a
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"testing"
+)
+
+func TestPropertyRegistry(t *testing.T) {
+	seen := make(map[string]string)
+	for _, v := range Properties() {
+		if len(v.Property) != 2 || v.Type == 0 || len(v.Object) != 2 {
+			t.Errorf("bad entry: %+v", v)
+		}
+		if name, ok := seen[string(v.Property)]; ok {
+			t.Errorf("property [% X] registered as %s and %s", v.Property, name, v.Name)
+		}
+		seen[string(v.Property)] = v.Name
+	}
+	if info, ok := LookupProperty(LabelFontSize); !ok || info.Type != PropertyU8 {
+		t.Errorf("LabelFontSize: %+v", info)
+	}
+}
+
+func TestSetProperty(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+
+	if err := m.SetProperty(1, LabelText, "hi"); err != nil {
+		t.Fatalf("set text: %s", err)
+	}
+	if err := m.SetProperty(1, LabelFontSize, 12); err != nil {
+		t.Fatalf("set U8: %s", err)
+	}
+	if err := m.SetProperty(2, SliderValue, int16(-3)); err != nil {
+		t.Fatalf("set S16: %s", err)
+	}
+	if err := m.SetProperty(1, LabelBackgroundG, color.RGBA{R: 1, G: 2, B: 3, A: 0xFF}); err != nil {
+		t.Fatalf("set colour: %s", err)
+	}
+	for _, v := range []struct {
+		id   int
+		prop GTT25PropertyType
+		want []byte
+	}{
+		{1, LabelFontSize, []byte{12}},
+		{2, SliderValue, []byte{0xFF, 0xFD}},
+		{1, LabelBackgroundG, []byte{2}},
+	} {
+		if got := e.Property(v.id, v.prop); !bytes.Equal(got, v.want) {
+			t.Errorf("property [% X]: [% X], want [% X]", v.prop, got, v.want)
+		}
+	}
+
+	sent := len(e.Requests())
+	var valueErr *PropertyValueError
+	if err := m.SetProperty(1, LabelFontSize, 300); !errors.As(err, &valueErr) {
+		t.Errorf("U8 out of range: %v", err)
+	}
+	if err := m.SetProperty(1, LabelText, 5); !errors.As(err, &valueErr) {
+		t.Errorf("int for Text: %v", err)
+	}
+	if err := m.SetProperty(1, SliderValue, "5"); !errors.As(err, &valueErr) {
+		t.Errorf("string for S16: %v", err)
+	}
+	if err := m.SetProperty(1, GTT25PropertyType{0x7F, 0x7F}, 1); !errors.Is(err, ErrUnknownProperty) {
+		t.Errorf("unknown property: %v", err)
+	}
+	if n := len(e.Requests()); n != sent {
+		t.Errorf("rejected values sent %d requests", n-sent)
+	}
+}
@@ -0,0 +1,134 @@
+package gtt43a
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"unicode/utf16"
+)
+
+// Response is a reply of the display device.
+type Response struct {
+	// Cmd is the command ID the device is answering.
+	Cmd byte
+	// SubCmd is the object method of a GTT2.5 (0xFA) reply, nil otherwise.
+	SubCmd []byte
+	// Status is the status code of a GTT2.5 (0xFA) reply, StatusSuccess
+	// for the other commands.
+	Status StatusCode
+	// Payload are the return values, after the sub-command and status.
+	Payload []byte
+}
+
+// NewResponse decodes a Frame received from the device.
+func NewResponse(frame Frame) (*Response, error) {
+	res := &Response{
+		Cmd:     frame.Cmd,
+		Status:  StatusSuccess,
+		Payload: frame.Payload,
+	}
+	if frame.Cmd == 0xFA {
+		if len(frame.Payload) < 3 {
+			return nil, fmt.Errorf("incomplete response: [% X]", frame.Bytes())
+		}
+		res.SubCmd = frame.Payload[0:2]
+		res.Status = StatusCode(frame.Payload[2])
+		res.Payload = frame.Payload[3:]
+	}
+	return res, nil
+}
+
+// Frame encodes the response back to the frame sent by the device.
+func (r *Response) Frame() Frame {
+	if r.Cmd != 0xFA {
+		return Frame{Cmd: r.Cmd, Payload: r.Payload}
+	}
+	payload := make([]byte, 0, len(r.Payload)+3)
+	payload = append(payload, r.SubCmd...)
+	payload = append(payload, byte(r.Status))
+	payload = append(payload, r.Payload...)
+	return Frame{Cmd: r.Cmd, Payload: payload}
+}
+
+// Bytes encodes the response as it was sent on the wire.
+func (r *Response) Bytes() []byte {
+	return r.Frame().Bytes()
+}
+
+// Err returns a *StatusError if the device rejected the request.
+func (r *Response) Err() error {
+	if r.Status == StatusSuccess {
+		return nil
+	}
+	return &StatusError{Code: r.Status, SubCmd: r.SubCmd}
+}
+
+// Uint8 decodes the payload as an unsigned 8 bits value.
+func (r *Response) Uint8() (uint8, error) {
+	if len(r.Payload) < 1 {
+		return 0, fmt.Errorf("bad response: [% X]", r.Bytes())
+	}
+	return r.Payload[0], nil
+}
+
+// Uint16 decodes the payload as an unsigned 16 bits big endian value.
+func (r *Response) Uint16() (uint16, error) {
+	if len(r.Payload) < 2 {
+		return 0, fmt.Errorf("bad response: [% X]", r.Bytes())
+	}
+	return binary.BigEndian.Uint16(r.Payload[0:2]), nil
+}
+
+// Int16 decodes the payload as a signed 16 bits big endian value.
+func (r *Response) Int16() (int16, error) {
+	v, err := r.Uint16()
+	return int16(v), err
+}
+
+// Bool decodes the payload as a boolean, any value but 0 is true.
+func (r *Response) Bool() (bool, error) {
+	v, err := r.Uint8()
+	return v != 0, err
+}
+
+// Int32 decodes the payload as a signed 32 bits big endian value.
+func (r *Response) Int32() (int32, error) {
+	if len(r.Payload) < 4 {
+		return 0, fmt.Errorf("bad response: [% X]", r.Bytes())
+	}
+	return int32(binary.BigEndian.Uint32(r.Payload[0:4])), nil
+}
+
+// Text decodes the payload as a text property: length (2 bytes, big endian)
+// followed by the UTF-16LE encoded text.
+func (r *Response) Text() (string, error) {
+	if len(r.Payload) < 2 {
+		return "", fmt.Errorf("bad response: [% X]", r.Bytes())
+	}
+	lenValue := int(binary.BigEndian.Uint16(r.Payload[0:2]))
+	value := r.Payload[2:]
+	if len(value) < lenValue || lenValue%2 != 0 {
+		return "", fmt.Errorf("bad response: [% X]", r.Bytes())
+	}
+	value16 := make([]uint16, 0, lenValue/2)
+	for i := 0; i < lenValue; i += 2 {
+		value16 = append(value16, binary.LittleEndian.Uint16(value[i:i+2]))
+	}
+	return string(utf16.Decode(value16)), nil
+}
+
+// sendRecvObject sends a GTT2.5 object command and checks the status code of
+// the reply.
+func (m *display) sendRecvObject(ctx context.Context, data []byte) (*Response, error) {
+	res, err := m.SendRecvContext(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if res.Cmd != 0xFA {
+		return nil, fmt.Errorf("wrong response: [% X]", res.Bytes())
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
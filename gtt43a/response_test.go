@@ -0,0 +1,93 @@
+package gtt43a
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		frame   Frame
+		want    Response
+		wantErr bool
+	}{
+		{
+			name:  "version",
+			frame: Frame{0x00, []byte{0x02, 0x05}},
+			want:  Response{Cmd: 0x00, Status: StatusSuccess, Payload: []byte{0x02, 0x05}},
+		},
+		{
+			name:  "GTT2.5 setter",
+			frame: Frame{0xFA, []byte{0x01, 0x06, 0xFE}},
+			want:  Response{Cmd: 0xFA, SubCmd: []byte{0x01, 0x06}, Status: StatusSuccess, Payload: []byte{}},
+		},
+		{
+			name:  "GTT2.5 getter",
+			frame: Frame{0xFA, []byte{0x01, 0x07, 0xFE, 0x01, 0x2C}},
+			want:  Response{Cmd: 0xFA, SubCmd: []byte{0x01, 0x07}, Status: StatusSuccess, Payload: []byte{0x01, 0x2C}},
+		},
+		{
+			name:  "GTT2.5 status code",
+			frame: Frame{0xFA, []byte{0x01, 0x06, 0xFC}},
+			want:  Response{Cmd: 0xFA, SubCmd: []byte{0x01, 0x06}, Status: StatusInvalidObjectID, Payload: []byte{}},
+		},
+		{
+			name:    "GTT2.5 incomplete",
+			frame:   Frame{0xFA, []byte{0x01, 0x06}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := NewResponse(tt.frame)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Cmd != tt.want.Cmd || res.Status != tt.want.Status ||
+				!bytes.Equal(res.SubCmd, tt.want.SubCmd) || !bytes.Equal(res.Payload, tt.want.Payload) {
+				t.Errorf("response: %+v, want %+v", res, tt.want)
+			}
+			if !bytes.Equal(res.Bytes(), tt.frame.Bytes()) {
+				t.Errorf("bytes: [% X], want [% X]", res.Bytes(), tt.frame.Bytes())
+			}
+		})
+	}
+}
+
+func TestResponseValues(t *testing.T) {
+	res := &Response{Cmd: 0xFA, Payload: []byte{0xFF, 0x38}}
+	if v, err := res.Uint16(); err != nil || v != 0xFF38 {
+		t.Errorf("Uint16: %d, %v", v, err)
+	}
+	if v, err := res.Int16(); err != nil || v != -200 {
+		t.Errorf("Int16: %d, %v", v, err)
+	}
+	if v, err := res.Uint8(); err != nil || v != 0xFF {
+		t.Errorf("Uint8: %d, %v", v, err)
+	}
+
+	res = &Response{Cmd: 0xFA, Payload: []byte{0xFF, 0xFE, 0x79, 0x60}}
+	if v, err := res.Int32(); err != nil || v != -100000 {
+		t.Errorf("Int32: %d, %v", v, err)
+	}
+	if _, err := (&Response{Cmd: 0xFA, Payload: []byte{0x00, 0x01}}).Int32(); err == nil {
+		t.Errorf("Int32: expected error on short payload")
+	}
+
+	res = &Response{Cmd: 0xFA, Payload: []byte{0x00, 0x06, 'C', 0x00, 0xED, 0x00, 'v', 0x00}}
+	if v, err := res.Text(); err != nil || v != "Cív" {
+		t.Errorf("Text: %q, %v", v, err)
+	}
+
+	res = &Response{Cmd: 0xFA, Payload: []byte{0x00, 0x06, 'C', 0x00}}
+	if _, err := res.Text(); err == nil {
+		t.Errorf("Text: expected error on truncated text")
+	}
+}
@@ -0,0 +1,139 @@
+package gtt43a
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// State of the display.
+type State uint32
+
+func (s State) String() string {
+	switch s {
+	case OPENED:
+		return "opened"
+	case CLOSED:
+		return "closed"
+	case LISTEN:
+		return "listening"
+	case UNRESPONSIVE:
+		return "unresponsive"
+	}
+	return fmt.Sprintf("State(%d)", uint32(s))
+}
+
+// State returns the current state of the display. Safe for concurrent use.
+func (m *display) State() State {
+	return m.state()
+}
+
+// StateChanges returns a channel that receives every change of the state,
+// and the function that stops the notifications and closes the channel.
+// Changes are dropped when the channel is full; call State to resync. Close
+// sends CLOSED and then closes every channel.
+func (m *display) StateChanges() (<-chan State, func()) {
+	ch := make(chan State, 8)
+	m.smux.Lock()
+	if m.stateChanges == nil {
+		m.stateChanges = make(map[chan State]struct{})
+	}
+	m.stateChanges[ch] = struct{}{}
+	m.smux.Unlock()
+
+	cancel := func() {
+		m.smux.Lock()
+		defer m.smux.Unlock()
+		if _, ok := m.stateChanges[ch]; ok {
+			delete(m.stateChanges, ch)
+			close(ch)
+		}
+	}
+	return ch, cancel
+}
+
+// closeStateChanges closes every channel returned by StateChanges.
+func (m *display) closeStateChanges() {
+	m.smux.Lock()
+	defer m.smux.Unlock()
+	for ch := range m.stateChanges {
+		delete(m.stateChanges, ch)
+		close(ch)
+	}
+}
+
+func (m *display) state() State {
+	return State(atomic.LoadUint32(&m.status))
+}
+
+// listening is true when the listener is running, responsive or not.
+func (m *display) listening() bool {
+	s := m.state()
+	return s == LISTEN || s == UNRESPONSIVE
+}
+
+func (m *display) setState(s State) {
+	old := State(atomic.SwapUint32(&m.status, uint32(s)))
+	if old != s {
+		m.notifyState(s)
+	}
+}
+
+// swapState changes the state to new only if it is old.
+func (m *display) swapState(old, new State) bool {
+	if !atomic.CompareAndSwapUint32(&m.status, uint32(old), uint32(new)) {
+		return false
+	}
+	if old != new {
+		m.notifyState(new)
+	}
+	return true
+}
+
+func (m *display) notifyState(s State) {
+	m.logger().Debug("state", "state", s)
+	m.smux.Lock()
+	defer m.smux.Unlock()
+	for ch := range m.stateChanges {
+		select {
+		case ch <- s:
+		default:
+		}
+	}
+}
+
+// heartbeat sends an Echo every interval while ctx is not done, and flags
+// the display UNRESPONSIVE after the configured number of consecutive misses.
+func (m *display) heartbeat(ctx context.Context, interval time.Duration) {
+	maxMisses := m.options.HeartbeatMisses
+	if maxMisses <= 0 {
+		maxMisses = 3
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	misses := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		reqCtx, cancel := context.WithTimeout(ctx, m.responseTimeout())
+		_, err := m.EchoContext(reqCtx, []byte("hb"))
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			misses++
+			m.logger().Warn("heartbeat missed", "misses", misses, "err", err)
+			if misses >= maxMisses {
+				m.swapState(LISTEN, UNRESPONSIVE)
+			}
+			continue
+		}
+		misses = 0
+		m.swapState(UNRESPONSIVE, LISTEN)
+	}
+}
@@ -0,0 +1,68 @@
+package gtt43a
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
+)
+
+func TestStateChanges(t *testing.T) {
+	e := emulator.New(nil)
+	defer e.Close()
+	m := NewDisplayWithTransport(e.Port(), &PortOptions{
+		ReadTimeout:       emulatorReadTimeout,
+		ResponseTimeout:   50 * time.Millisecond,
+		HeartbeatInterval: 20 * time.Millisecond,
+		HeartbeatMisses:   2,
+	})
+	changes, cancel := m.StateChanges()
+	defer cancel()
+	other, cancelOther := m.StateChanges()
+	cancelOther()
+	if _, ok := <-other; ok {
+		t.Errorf("cancelled channel not closed")
+	}
+	if s := m.State(); s != CLOSED {
+		t.Fatalf("state: %s", s)
+	}
+	if err := m.Open(); err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+	waitStateChange(t, changes, OPENED)
+	waitStateChange(t, changes, LISTEN)
+
+	// the panel stops answering the heartbeats
+	e.Drop(2)
+	waitStateChange(t, changes, UNRESPONSIVE)
+	waitStateChange(t, changes, LISTEN)
+
+	m.StopListen()
+	waitStateChange(t, changes, OPENED)
+	m.Close()
+	waitStateChange(t, changes, CLOSED)
+	if _, ok := <-changes; ok {
+		t.Errorf("channel not closed by Close")
+	}
+}
+
+func waitStateChange(t *testing.T, changes <-chan State, want State) {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case s, ok := <-changes:
+			if !ok {
+				t.Fatalf("channel closed waiting state %s", want)
+			}
+			if s == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting state %s", want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package gtt43a
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// EventFilter selects the events of a subscription. Empty lists match any
+// event.
+type EventFilter struct {
+	Types  []EventType
+	ObjIds []uint16
+	// Buffer is the number of events queued for a slow subscriber before
+	// they are dropped, 0 means 16.
+	Buffer int
+}
+
+// Match reports whether the filter selects event.
+func (f EventFilter) Match(event *Event) bool {
+	if len(f.Types) > 0 {
+		found := false
+		for _, v := range f.Types {
+			if v == event.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.ObjIds) > 0 {
+		found := false
+		for _, v := range f.ObjIds {
+			if v == event.ObjId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+type subscriber struct {
+	filter EventFilter
+	ch     chan *Event
+}
+
+// eventHub fans out the events read by the listeners to the subscribers. It
+// belongs to the display, so subscriptions survive StopListen and reconnects.
+type eventHub struct {
+	mux         sync.Mutex
+	subscribers map[*subscriber]struct{}
+	dropped     uint64
+}
+
+func (h *eventHub) subscribe(filter EventFilter) (*subscriber, func()) {
+	if filter.Buffer <= 0 {
+		filter.Buffer = 16
+	}
+	sub := &subscriber{filter: filter, ch: make(chan *Event, filter.Buffer)}
+	h.mux.Lock()
+	if h.subscribers == nil {
+		h.subscribers = make(map[*subscriber]struct{})
+	}
+	h.subscribers[sub] = struct{}{}
+	h.mux.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mux.Lock()
+			defer h.mux.Unlock()
+			delete(h.subscribers, sub)
+			close(sub.ch)
+		})
+	}
+	return sub, cancel
+}
+
+// publish queues event to every matching subscriber, without blocking. It
+// returns the number of subscribers that dropped it.
+func (h *eventHub) publish(event *Event) int {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	dropped := 0
+	for sub := range h.subscribers {
+		if !sub.filter.Match(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			dropped++
+		}
+	}
+	atomic.AddUint64(&h.dropped, uint64(dropped))
+	return dropped
+}
+
+// Subscribe returns a channel with the events selected by filter, and the
+// function that ends the subscription and closes the channel. Every
+// subscriber gets its own copy of the events, queued up to filter.Buffer;
+// events are dropped, and counted by DroppedEvents, when the queue is full.
+// Subscriptions survive StopListen and reconnects, events are received only
+// while listening.
+func (m *display) Subscribe(filter EventFilter) (<-chan *Event, func()) {
+	sub, cancel := m.events.subscribe(filter)
+	return sub.ch, cancel
+}
+
+// DroppedEvents returns the number of events dropped because a subscriber
+// queue was full.
+func (m *display) DroppedEvents() uint64 {
+	return atomic.LoadUint64(&m.events.dropped)
+}
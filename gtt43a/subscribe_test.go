@@ -0,0 +1,71 @@
+package gtt43a
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribe(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+	buttons, cancelButtons := m.Subscribe(EventFilter{Types: []EventType{ButtonClick}})
+	defer cancelButtons()
+	object7, cancelObject7 := m.Subscribe(EventFilter{ObjIds: []uint16{7}})
+	slow, cancelSlow := m.Subscribe(EventFilter{Buffer: 1})
+	defer cancelSlow()
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen: %s", err)
+	}
+
+	e.InjectEvent(0x15, 3, []byte{0x01})
+	e.InjectRegionTouch(1, 7)
+	e.InjectEvent(0x15, 7, []byte{0x01})
+
+	if evt := nextEvent(t, buttons); evt.ObjId != 3 {
+		t.Errorf("buttons: %+v", evt)
+	}
+	if evt := nextEvent(t, buttons); evt.ObjId != 7 {
+		t.Errorf("buttons: %+v", evt)
+	}
+	if evt := nextEvent(t, object7); evt.Type != RegionTouch {
+		t.Errorf("object 7: %+v", evt)
+	}
+	if evt := nextEvent(t, object7); evt.Type != ButtonClick {
+		t.Errorf("object 7: %+v", evt)
+	}
+	if n := m.DroppedEvents(); n != 2 {
+		t.Errorf("dropped: %d, want 2", n)
+	}
+	if evt := nextEvent(t, slow); evt.ObjId != 3 {
+		t.Errorf("slow: %+v", evt)
+	}
+
+	cancelObject7()
+	cancelObject7()
+	if _, ok := <-object7; ok {
+		t.Errorf("channel not closed by cancel")
+	}
+
+	// subscriptions survive a new listen
+	m.StopListen()
+	if err := m.Listen(); err != nil {
+		t.Fatalf("listen again: %s", err)
+	}
+	e.InjectEvent(0x15, 9, nil)
+	if evt := nextEvent(t, buttons); evt.ObjId != 9 {
+		t.Errorf("buttons after listen: %+v", evt)
+	}
+}
+
+func nextEvent(t *testing.T, ch <-chan *Event) *Event {
+	t.Helper()
+	select {
+	case evt, ok := <-ch:
+		if !ok {
+			t.Fatalf("channel closed")
+		}
+		return evt
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting event")
+	}
+	return nil
+}
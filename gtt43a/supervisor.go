@@ -0,0 +1,189 @@
+package gtt43a
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ConnState is the state of the connection kept by Supervise.
+type ConnState int
+
+const (
+	// Connected: the port is open and listening.
+	Connected ConnState = iota
+	// Disconnected: the listener stopped on read errors, the port is lost.
+	Disconnected
+	// Reconnecting: an attempt to reopen the port failed, retrying.
+	Reconnecting
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case Connected:
+		return "connected"
+	case Disconnected:
+		return "disconnected"
+	case Reconnecting:
+		return "reconnecting"
+	}
+	return fmt.Sprintf("ConnState(%d)", int(s))
+}
+
+// SuperviseOptions of Supervise.
+type SuperviseOptions struct {
+	// MinBackoff is the wait after the first failed attempt to reopen the
+	// port, doubled after every failure up to MaxBackoff. 0 means 500 ms.
+	MinBackoff time.Duration
+	// MaxBackoff, 0 means 30 s.
+	MaxBackoff time.Duration
+	// RerunScript runs again the last script run with RunScript after
+	// reconnecting, to restore the screen of a panel reset by the unplug.
+	RerunScript bool
+}
+
+// Supervise opens and listens the display, if it is not listening yet, and
+// keeps it listening: when the listener stops on read errors (unplugged
+// USB-serial adapter) the port is closed and reopened with backoff, and
+// listening restarts. The channel receives the changes of the connection,
+// it is closed when ctx is done, or on StopListen or Close.
+//
+// The channel returned by Events is closed on disconnection, call Events
+// again after Connected. Ports opened with NewDisplay are reopened by name,
+// NewDisplayWithDialer dials a new transport.
+func (m *display) Supervise(ctx context.Context, opt *SuperviseOptions) (<-chan ConnState, error) {
+	options := SuperviseOptions{}
+	if opt != nil {
+		options = *opt
+	}
+	if options.MinBackoff <= 0 {
+		options.MinBackoff = 500 * time.Millisecond
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 30 * time.Second
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	sup := &supervision{cancel: cancel}
+	m.lmux.Lock()
+	if m.supervision != nil {
+		m.lmux.Unlock()
+		cancel()
+		return nil, fmt.Errorf("error: display already supervised")
+	}
+	m.supervision = sup
+	m.lmux.Unlock()
+	stop := func() {
+		cancel()
+		m.lmux.Lock()
+		if m.supervision == sup {
+			m.supervision = nil
+		}
+		m.lmux.Unlock()
+	}
+
+	if !m.listening() {
+		if err := m.openContext(ctx); err != nil {
+			stop()
+			return nil, err
+		}
+		if err := m.ListenWithContext(ctx); err != nil {
+			stop()
+			return nil, err
+		}
+	}
+
+	states := make(chan ConnState, 8)
+	notify := func(state ConnState) {
+		m.logger().Info("connection", "state", state)
+		select {
+		case states <- state:
+		default:
+			m.logger().Warn("connection state dropped", "state", state)
+		}
+	}
+
+	l := m.currentListener()
+	go func() {
+		defer close(states)
+		defer stop()
+		for {
+			if l != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-l.done:
+				}
+				if l.err == nil {
+					// stopped by StopListen or Close
+					return
+				}
+			}
+			notify(Disconnected)
+			m.dropPort()
+
+			backoff := options.MinBackoff
+			for {
+				err := m.reconnect(ctx, &options)
+				if err == nil {
+					break
+				}
+				if ctx.Err() != nil {
+					return
+				}
+				m.logger().Warn("reconnect", "err", err, "backoff", backoff)
+				notify(Reconnecting)
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(backoff):
+				}
+				backoff *= 2
+				if backoff > options.MaxBackoff {
+					backoff = options.MaxBackoff
+				}
+			}
+			l = m.currentListener()
+			notify(Connected)
+		}
+	}()
+	return states, nil
+}
+
+// supervision is a running Supervise.
+type supervision struct {
+	cancel func()
+}
+
+// dropPort closes a lost port, so that it can be opened again.
+func (m *display) dropPort() {
+	m.lmux.Lock()
+	port := m.port
+	m.port = nil
+	if m.state() == OPENED {
+		m.setState(CLOSED)
+	}
+	m.lmux.Unlock()
+	if port != nil {
+		port.Close()
+	}
+}
+
+// reconnect reopens the port and restarts listening.
+func (m *display) reconnect(ctx context.Context, opt *SuperviseOptions) error {
+	if err := m.openContext(ctx); err != nil {
+		return err
+	}
+	if err := m.ListenWithContext(ctx); err != nil {
+		m.dropPort()
+		return err
+	}
+	m.lmux.Lock()
+	script := m.script
+	m.lmux.Unlock()
+	if opt.RerunScript && script != "" {
+		if err := m.RunScript(script); err != nil {
+			m.logger().Warn("rerun script", "file", script, "err", err)
+		}
+	}
+	return nil
+}
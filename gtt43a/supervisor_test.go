@@ -0,0 +1,137 @@
+package gtt43a
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
+)
+
+func TestSupervise(t *testing.T) {
+	var mux sync.Mutex
+	var current *emulator.Emulator
+	dial := func() (io.ReadWriteCloser, error) {
+		mux.Lock()
+		defer mux.Unlock()
+		if current == nil {
+			return nil, io.ErrClosedPipe
+		}
+		return current.Port(), nil
+	}
+	plug := func(e *emulator.Emulator) {
+		mux.Lock()
+		defer mux.Unlock()
+		current = e
+	}
+
+	first := emulator.New(nil)
+	defer first.Close()
+	plug(first)
+	m := NewDisplayWithDialer(dial, &PortOptions{ReadTimeout: emulatorReadTimeout})
+	defer m.Close()
+
+	states, err := m.Supervise(context.Background(), &SuperviseOptions{
+		MinBackoff:  10 * time.Millisecond,
+		RerunScript: true,
+	})
+	if err != nil {
+		t.Fatalf("supervise: %s", err)
+	}
+	if err := m.RunScript("Screen1.bin"); err != nil {
+		t.Fatalf("run script: %s", err)
+	}
+
+	// unplug: the listener stops on read errors
+	plug(nil)
+	first.Close()
+	waitState(t, states, Disconnected)
+	waitState(t, states, Reconnecting)
+
+	second := emulator.New(nil)
+	defer second.Close()
+	plug(second)
+	waitState(t, states, Connected)
+
+	if got := second.Script(); got != "Screen1.bin" {
+		t.Errorf("script not run again: %q", got)
+	}
+	if _, err := m.Echo([]byte("back")); err != nil {
+		t.Errorf("echo after reconnect: %s", err)
+	}
+
+	m.StopListen()
+	select {
+	case _, ok := <-states:
+		if ok {
+			t.Errorf("unexpected state")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("states not closed on StopListen")
+	}
+}
+
+// A transport that reports io.EOF when the peer is gone, with the default
+// ReadTimeout 0: the disconnection must still be seen.
+func TestSuperviseEOF(t *testing.T) {
+	peers := make(chan net.Conn, 4)
+	dial := func() (io.ReadWriteCloser, error) {
+		host, dev := net.Pipe()
+		go func() {
+			buf := make([]byte, 64)
+			for {
+				n, err := dev.Read(buf)
+				if err != nil {
+					return
+				}
+				if n < 2 || buf[0] != 0xFE || buf[1] != 0xFF {
+					continue
+				}
+				res := []byte{0xFC, 0xFF, 0x00, byte(n - 2)}
+				if _, err := dev.Write(append(res, buf[2:n]...)); err != nil {
+					return
+				}
+			}
+		}()
+		peers <- dev
+		return host, nil
+	}
+	m := NewDisplayWithDialer(dial, nil)
+	defer m.Close()
+
+	states, err := m.Supervise(context.Background(), &SuperviseOptions{MinBackoff: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("supervise: %s", err)
+	}
+	first := <-peers
+	if _, err := m.Echo([]byte("one")); err != nil {
+		t.Fatalf("echo: %s", err)
+	}
+
+	first.Close()
+	waitState(t, states, Disconnected)
+	waitState(t, states, Connected)
+	second := <-peers
+	defer second.Close()
+	if _, err := m.Echo([]byte("two")); err != nil {
+		t.Errorf("echo after reconnect: %s", err)
+	}
+}
+
+func waitState(t *testing.T, states <-chan ConnState, want ConnState) {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case state := <-states:
+			if state == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timeout waiting state %s", want)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package gtt43a
 
-import "encoding/binary"
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+)
 
 //Print text data in actual (x,y) point in display area
 func (m *display) Text(data string) error {
@@ -27,8 +31,26 @@ func (m *display) TextInsertPoint(x, y int) error {
 }
 
 //Get actual (x,y) point
-func (m *display) GetTextPoint() ([]byte, error) {
-	return m.SendRecvCmd(0x7A, nil)
+func (m *display) GetTextPoint() (x, y int, err error) {
+	return m.GetTextPointContext(context.Background())
+}
+
+// GetTextPointContext is GetTextPoint bounded by ctx.
+func (m *display) GetTextPointContext(ctx context.Context) (x, y int, err error) {
+	var res *Response
+	err = m.retry(ctx, func(ctx context.Context) (err error) {
+		res, err = m.SendRecvCmdContext(ctx, 0x7A, nil)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(res.Payload) < 4 {
+		return 0, 0, fmt.Errorf("bad response: [% X]", res.Bytes())
+	}
+	x = int(binary.BigEndian.Uint16(res.Payload[0:2]))
+	y = int(binary.BigEndian.Uint16(res.Payload[2:4]))
+	return x, y, nil
 }
 
 //Print data text in this (x,y) point
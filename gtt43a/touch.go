@@ -0,0 +1,57 @@
+package gtt43a
+
+import "fmt"
+
+// TouchReportingStyle selects the touch reports sent by the display, see
+// ChangeTouchReporting.
+type TouchReportingStyle byte
+
+const (
+	// TouchReportRegion reports the touches of the regions (0xFC 0x87).
+	TouchReportRegion TouchReportingStyle = iota
+	// TouchReportCoordinate reports the coordinates of every touch (0xFC 0x86).
+	TouchReportCoordinate
+	// TouchReportRegionAndCoordinate sends both reports.
+	TouchReportRegionAndCoordinate
+)
+
+func (s TouchReportingStyle) String() string {
+	switch s {
+	case TouchReportRegion:
+		return "region"
+	case TouchReportCoordinate:
+		return "coordinate"
+	case TouchReportRegionAndCoordinate:
+		return "region and coordinate"
+	}
+	return fmt.Sprintf("TouchReportingStyle(%d)", byte(s))
+}
+
+// TouchKind of a touch report.
+type TouchKind int
+
+const (
+	TouchRelease TouchKind = iota
+	TouchPress
+	TouchDrag
+)
+
+func (k TouchKind) String() string {
+	switch k {
+	case TouchRelease:
+		return "release"
+	case TouchPress:
+		return "press"
+	case TouchDrag:
+		return "drag"
+	}
+	return fmt.Sprintf("TouchKind(%d)", int(k))
+}
+
+// TouchEvent is the payload of RegionTouch and CoordinateTouch events. X and
+// Y are 0 in region reports, Region is -1 in coordinate reports.
+type TouchEvent struct {
+	Kind   TouchKind
+	X, Y   int
+	Region int
+}
@@ -0,0 +1,173 @@
+package gtt43a
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Tx queues the property writes of an object between BaseObjectBeginUpdate
+// and BaseObjectEndUpdate, see Update.
+type Tx struct {
+	id     int
+	writes []txWrite
+}
+
+type txWrite struct {
+	prpType GTT25PropertyType
+	data    []byte
+	// err rejects the write before sending it.
+	err error
+}
+
+// ID of the object updated.
+func (tx *Tx) ID() int {
+	return tx.id
+}
+
+func (tx *Tx) queue(prpType GTT25PropertyType, data []byte) {
+	tx.writes = append(tx.writes, txWrite{prpType: prpType, data: data})
+}
+
+// SetPropertyValueU16 queues a write of ApduSetPropertyValueU16.
+func (tx *Tx) SetPropertyValueU16(prpType GTT25PropertyType, value int) {
+	tx.queue(prpType, ApduSetPropertyValueU16(tx.id, prpType, value))
+}
+
+// SetPropertyValueS16 queues a write of ApduSetPropertyValueS16.
+func (tx *Tx) SetPropertyValueS16(prpType GTT25PropertyType, value int) {
+	tx.queue(prpType, ApduSetPropertyValueS16(tx.id, prpType, value))
+}
+
+// SetPropertyValueU8 queues a write of ApduSetPropertyValueU8.
+func (tx *Tx) SetPropertyValueU8(prpType GTT25PropertyType, value int) {
+	tx.queue(prpType, ApduSetPropertyValueU8(tx.id, prpType, value))
+}
+
+// SetPropertyValueS32 queues a write of ApduSetPropertyValueS32. A value out
+// of the S32 range is not sent, it fails with a *PropertyValueError.
+func (tx *Tx) SetPropertyValueS32(prpType GTT25PropertyType, value int) {
+	if !fitsS32(int64(value)) {
+		err := &PropertyValueError{Property: prpType, Type: PropertyS32, Value: value}
+		tx.writes = append(tx.writes, txWrite{prpType: prpType, err: err})
+		return
+	}
+	tx.queue(prpType, ApduSetPropertyValueS32(tx.id, prpType, value))
+}
+
+// SetPropertyBool queues a write of ApduSetPropertyBool.
+func (tx *Tx) SetPropertyBool(prpType GTT25PropertyType, value bool) {
+	tx.queue(prpType, ApduSetPropertyBool(tx.id, prpType, value))
+}
+
+// SetPropertyText queues a write of ApduSetPropertyText.
+func (tx *Tx) SetPropertyText(prpType GTT25PropertyType, text string) {
+	tx.queue(prpType, ApduSetPropertyText(tx.id, prpType, text))
+}
+
+// PropertyError is a property write of an Update rejected by the device, or
+// not sent because its value is not valid.
+type PropertyError struct {
+	Property GTT25PropertyType
+	Err      error
+}
+
+func (e *PropertyError) Error() string {
+	return fmt.Sprintf("property [% X]: %s", e.Property.Value(), e.Err)
+}
+
+func (e *PropertyError) Unwrap() error {
+	return e.Err
+}
+
+// UpdateError lists the failures of an Update. errors.Is matches any of them.
+type UpdateError struct {
+	ID int
+	// Failed are the property writes rejected, in the order queued.
+	Failed []*PropertyError
+	// End is the error of BaseObjectEndUpdate, if it failed.
+	End error
+}
+
+func (e *UpdateError) Error() string {
+	msgs := make([]string, 0)
+	for _, v := range e.Failed {
+		msgs = append(msgs, v.Error())
+	}
+	if e.End != nil {
+		msgs = append(msgs, fmt.Sprintf("end update: %s", e.End))
+	}
+	return fmt.Sprintf("update object %d: %s", e.ID, strings.Join(msgs, "; "))
+}
+
+func (e *UpdateError) Is(target error) bool {
+	for _, v := range e.Failed {
+		if errors.Is(v.Err, target) {
+			return true
+		}
+	}
+	return e.End != nil && errors.Is(e.End, target)
+}
+
+// Update runs fn between BaseObjectBeginUpdate and BaseObjectEndUpdate of
+// the object id, then sends the property writes queued in tx, pipelined when
+// the display is listening. EndUpdate runs even if fn fails or panics.
+//
+// The error is the error of BeginUpdate or fn, when they fail (the queued
+// writes are then discarded), else an *UpdateError if any write or EndUpdate
+// failed.
+func (m *display) Update(id int, fn func(tx *Tx) error) error {
+	return m.UpdateContext(context.Background(), id, fn)
+}
+
+// UpdateContext is Update bounded by ctx. EndUpdate is not: it is sent even
+// when ctx is done, so the object is not left in update mode.
+func (m *display) UpdateContext(ctx context.Context, id int, fn func(tx *Tx) error) (err error) {
+	if err := m.BaseObjectBeginUpdateContext(ctx, id); err != nil {
+		return err
+	}
+	updateErr := &UpdateError{ID: id}
+	defer func() {
+		if errEnd := m.BaseObjectEndUpdate(id); errEnd != nil {
+			updateErr.End = errEnd
+		}
+		if err == nil && (len(updateErr.Failed) > 0 || updateErr.End != nil) {
+			err = updateErr
+		}
+	}()
+
+	tx := &Tx{id: id}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if m.listening() {
+		futures := make([]*Future, len(tx.writes))
+		for i, w := range tx.writes {
+			if w.err == nil {
+				futures[i] = m.SendRecvAsync(w.data)
+			}
+		}
+		for i, f := range futures {
+			err := tx.writes[i].err
+			if f != nil {
+				_, err = f.WaitContext(ctx)
+			}
+			if err != nil {
+				updateErr.Failed = append(updateErr.Failed, &PropertyError{Property: tx.writes[i].prpType, Err: err})
+			}
+		}
+		return nil
+	}
+	for _, w := range tx.writes {
+		if w.err != nil {
+			updateErr.Failed = append(updateErr.Failed, &PropertyError{Property: w.prpType, Err: w.err})
+			continue
+		}
+		if _, err := m.sendRecvObject(ctx, w.data); err != nil {
+			updateErr.Failed = append(updateErr.Failed, &PropertyError{Property: w.prpType, Err: err})
+		}
+	}
+	return nil
+}
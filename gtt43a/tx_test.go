@@ -0,0 +1,112 @@
+package gtt43a
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dumacp/matrixorbital/gtt43a/emulator"
+)
+
+func TestUpdate(t *testing.T) {
+	for _, listen := range []bool{false, true} {
+		m, e := newEmulatedDisplay(t, &emulator.Options{Strict: true})
+		e.AddObject(5, ObjectType_VisualBitmap)
+		if listen {
+			if err := m.Listen(); err != nil {
+				t.Fatalf("listen: %s", err)
+			}
+		}
+
+		err := m.Update(5, func(tx *Tx) error {
+			tx.SetPropertyValueU16(Width, 120)
+			tx.SetPropertyValueS16(Left, -3)
+			tx.SetPropertyValueU8(Enabled, 1)
+			tx.SetPropertyText(LabelText, "ok")
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("listen %v: update: %s", listen, err)
+		}
+		if v, err := m.GetPropertyValueS16(5, Left)(); err != nil || v != -3 {
+			t.Errorf("listen %v: left %d, %v", listen, v, err)
+		}
+
+		// a rejected write doesn't stop the others, and is reported
+		e.FailNext(emulator.StatusSuccess) // BeginUpdate
+		e.FailNext(emulator.StatusSuccess)
+		e.FailNext(emulator.StatusOutOfRange)
+		err = m.Update(5, func(tx *Tx) error {
+			tx.SetPropertyValueU16(Width, 1)
+			tx.SetPropertyValueU16(Height, 0xFFFF)
+			tx.SetPropertyValueU16(Top, 7)
+			return nil
+		})
+		var updateErr *UpdateError
+		if !errors.As(err, &updateErr) || !errors.Is(err, ErrOutOfRange) {
+			t.Fatalf("listen %v: expected UpdateError, got %v", listen, err)
+		}
+		if len(updateErr.Failed) != 1 || string(updateErr.Failed[0].Property) != string(Height) {
+			t.Errorf("listen %v: failed %v", listen, updateErr.Failed)
+		}
+		if v, _ := m.GetPropertyValueU16(5, Top)(); v != 7 {
+			t.Errorf("listen %v: top %d", listen, v)
+		}
+
+		// an S32 value out of range is reported, not sent
+		before := len(e.Requests())
+		big := int64(1) << 31
+		err = m.Update(5, func(tx *Tx) error {
+			tx.SetPropertyValueS32(s32Property, int(big))
+			return nil
+		})
+		var valueErr *PropertyValueError
+		if !errors.As(err, &updateErr) || len(updateErr.Failed) != 1 || !errors.As(updateErr.Failed[0], &valueErr) {
+			t.Errorf("listen %v: expected PropertyValueError, got %v", listen, err)
+		}
+		checkBeginEnd(t, e.Requests()[before:])
+
+		// EndUpdate runs after an error of fn, and the writes are discarded
+		before = len(e.Requests())
+		errFn := errors.New("fn failed")
+		err = m.Update(5, func(tx *Tx) error {
+			tx.SetPropertyValueU16(Width, 2)
+			return errFn
+		})
+		if err != errFn {
+			t.Errorf("listen %v: expected fn error, got %v", listen, err)
+		}
+		checkBeginEnd(t, e.Requests()[before:])
+	}
+}
+
+func TestUpdatePanic(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("panic not propagated")
+		}
+		checkBeginEnd(t, e.Requests())
+	}()
+	m.Update(5, func(tx *Tx) error {
+		panic("boom")
+	})
+}
+
+func TestUpdateErrorMessage(t *testing.T) {
+	err := &UpdateError{ID: 3, Failed: []*PropertyError{{Property: LabelText, Err: ErrObjectNotFound}}, End: ErrorDevTimeout}
+	if msg := err.Error(); !strings.Contains(msg, "object 3") || !strings.Contains(msg, "09 06") || !strings.Contains(msg, "end update") {
+		t.Errorf("message: %s", msg)
+	}
+	if !errors.Is(err, ErrorDevTimeout) || errors.Is(err, ErrOutOfRange) {
+		t.Errorf("errors.Is")
+	}
+}
+
+// checkBeginEnd checks that the requests are BeginUpdate and EndUpdate only.
+func checkBeginEnd(t *testing.T, requests [][]byte) {
+	t.Helper()
+	if len(requests) != 2 || string(requests[0][2:4]) != string(Begin_Update) || string(requests[1][2:4]) != string(End_Update) {
+		t.Errorf("requests: [% X]", requests)
+	}
+}
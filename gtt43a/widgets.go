@@ -0,0 +1,107 @@
+package gtt43a
+
+import "image/color"
+
+// Label is a GTT2.5 label object of the display.
+type Label struct {
+	Display Display
+	ID      int
+}
+
+// SetText sets LabelText.
+func (w Label) SetText(text string) error {
+	return w.Display.SetPropertyText(w.ID, LabelText)(text)
+}
+
+// SetFontSize sets LabelFontSize.
+func (w Label) SetFontSize(size int) error {
+	return w.Display.SetPropertyValueU8(w.ID, LabelFontSize)(size)
+}
+
+// SetBackground sets LabelBackgroundR, G and B in a single update, the alpha
+// is ignored.
+func (w Label) SetBackground(c color.RGBA) error {
+	return w.Display.Update(w.ID, func(tx *Tx) error {
+		tx.SetPropertyValueU8(LabelBackgroundR, int(c.R))
+		tx.SetPropertyValueU8(LabelBackgroundG, int(c.G))
+		tx.SetPropertyValueU8(LabelBackgroundB, int(c.B))
+		return nil
+	})
+}
+
+// Button is a GTT2.5 button object of the display.
+type Button struct {
+	Display Display
+	ID      int
+}
+
+// SetText sets ButtonText.
+func (w Button) SetText(text string) error {
+	return w.Display.SetPropertyText(w.ID, ButtonText)(text)
+}
+
+// State returns ButtonState.
+func (w Button) State() (int, error) {
+	state, err := w.Display.GetPropertyValueU8(w.ID, ButtonState)()
+	return int(state), err
+}
+
+// SetState sets ButtonState.
+func (w Button) SetState(state int) error {
+	return w.Display.SetPropertyValueU8(w.ID, ButtonState)(state)
+}
+
+// SetEnabled sets the Enabled property of the button.
+func (w Button) SetEnabled(enabled bool) error {
+	return w.Display.SetPropertyBool(w.ID, Enabled)(enabled)
+}
+
+// Slider is a GTT2.5 slider object of the display.
+type Slider struct {
+	Display Display
+	ID      int
+}
+
+// Value returns SliderValue.
+func (w Slider) Value() (int, error) {
+	value, err := w.Display.GetPropertyValueS16(w.ID, SliderValue)()
+	return int(value), err
+}
+
+// SetValue sets SliderValue.
+func (w Slider) SetValue(value int) error {
+	return w.Display.SetPropertyValueS16(w.ID, SliderValue)(value)
+}
+
+// SetLabelText sets SliderLabelText.
+func (w Slider) SetLabelText(text string) error {
+	return w.Display.SetPropertyText(w.ID, SliderLabelText)(text)
+}
+
+// Gauge is a GTT2.5 gauge object of the display.
+type Gauge struct {
+	Display Display
+	ID      int
+}
+
+// Value returns GaugeValue.
+func (w Gauge) Value() (int, error) {
+	value, err := w.Display.GetPropertyValueS16(w.ID, GaugeValue)()
+	return int(value), err
+}
+
+// SetValue sets GaugeValue.
+func (w Gauge) SetValue(value int) error {
+	return w.Display.SetPropertyValueS16(w.ID, GaugeValue)(value)
+}
+
+// Toggle is a toggle object of the display.
+type Toggle struct {
+	Display Display
+	ID      int
+}
+
+// State returns the state of the toggle, see GetToggleState.
+func (w Toggle) State() (int, error) {
+	return w.Display.GetToggleState(w.ID)
+}
@@ -0,0 +1,80 @@
+package gtt43a
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+)
+
+func TestWidgets(t *testing.T) {
+	m, e := newEmulatedDisplay(t, nil)
+
+	label := Label{Display: m, ID: 1}
+	if err := label.SetText("Température"); err != nil {
+		t.Fatalf("label text: %s", err)
+	}
+	if text, err := m.GetPropertyText(1, LabelText)(); err != nil || text != "Température" {
+		t.Errorf("label text: %q, %v", text, err)
+	}
+	if err := label.SetFontSize(14); err != nil {
+		t.Fatalf("label font size: %s", err)
+	}
+	if err := label.SetBackground(color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xFF}); err != nil {
+		t.Fatalf("label background: %s", err)
+	}
+	for prop, want := range map[string][]byte{
+		string(LabelFontSize):    {14},
+		string(LabelBackgroundR): {0x10},
+		string(LabelBackgroundG): {0x20},
+		string(LabelBackgroundB): {0x30},
+	} {
+		if v := e.Property(1, []byte(prop)); !bytes.Equal(v, want) {
+			t.Errorf("label property [% X]: [% X], want [% X]", prop, v, want)
+		}
+	}
+
+	button := Button{Display: m, ID: 2}
+	if err := button.SetText("OK"); err != nil {
+		t.Fatalf("button text: %s", err)
+	}
+	if text, err := m.GetPropertyText(2, ButtonText)(); err != nil || text != "OK" {
+		t.Errorf("button text: %q, %v", text, err)
+	}
+	if err := button.SetEnabled(true); err != nil {
+		t.Fatalf("button enabled: %s", err)
+	}
+	if v := e.Property(2, Enabled); !bytes.Equal(v, []byte{1}) {
+		t.Errorf("button enabled: [% X]", v)
+	}
+	if err := button.SetState(1); err != nil {
+		t.Fatalf("button state: %s", err)
+	}
+	if state, err := button.State(); err != nil || state != 1 {
+		t.Errorf("button state: %d, %v", state, err)
+	}
+
+	slider := Slider{Display: m, ID: 3}
+	if err := slider.SetValue(-20); err != nil {
+		t.Fatalf("slider value: %s", err)
+	}
+	if value, err := slider.Value(); err != nil || value != -20 {
+		t.Errorf("slider value: %d, %v", value, err)
+	}
+
+	gauge := Gauge{Display: m, ID: 4}
+	if err := gauge.SetValue(75); err != nil {
+		t.Fatalf("gauge value: %s", err)
+	}
+	if value, err := gauge.Value(); err != nil || value != 75 {
+		t.Errorf("gauge value: %d, %v", value, err)
+	}
+
+	toggle := Toggle{Display: m, ID: 5}
+	if state, err := toggle.State(); err != nil || state != 0 {
+		t.Errorf("toggle state: %d, %v", state, err)
+	}
+	e.SetToggleState(5, 1)
+	if state, err := toggle.State(); err != nil || state != 1 {
+		t.Errorf("toggle state: %d, %v", state, err)
+	}
+}